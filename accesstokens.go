@@ -0,0 +1,58 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+)
+
+// lists the personal access tokens of the site's users, requires a site or server administrator
+func (api *API) ListPersonalAccessTokens(siteId string) ([]PersonalAccessToken, error) {
+	return api.ListPersonalAccessTokensContext(context.Background(), siteId)
+}
+
+func (api *API) ListPersonalAccessTokensContext(ctx context.Context, siteId string) ([]PersonalAccessToken, error) {
+	totalAvailable := 1
+	tokens := []PersonalAccessToken{}
+	for i := 1; len(tokens) < totalAvailable; i++ {
+		requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/personalAccessTokens?pageSize=%v&pageNumber=%v", api.Server, api.Version, siteId, PAGESIZE, i)
+		headers := make(map[string]string)
+		response := QueryPersonalAccessTokensResponse{}
+		if err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers); err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, response.PersonalAccessTokens.PersonalAccessTokens...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return tokens, nil
+}
+
+func (api *API) RevokePersonalAccessToken(siteId, tokenId string) error {
+	return api.RevokePersonalAccessTokenContext(context.Background(), siteId, tokenId)
+}
+
+func (api *API) RevokePersonalAccessTokenContext(ctx context.Context, siteId, tokenId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/personalAccessTokens/%s", api.Server, api.Version, siteId, tokenId)
+	return api.delete(ctx, requestUrl)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_authentication.htm#revoke_administrator_personal_access_tokens
+// revokes the personal access tokens of every server administrator, Tableau Server only
+func (api *API) RevokeAllServerAdminPATs() error {
+	return api.RevokeAllServerAdminPATsContext(context.Background())
+}
+
+func (api *API) RevokeAllServerAdminPATsContext(ctx context.Context) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/auth/serverAdminAccessTokens", api.Server, api.Version)
+	return api.delete(ctx, requestUrl)
+}
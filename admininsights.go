@@ -0,0 +1,85 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Tableau Cloud creates this project on every site and keeps its datasources refreshed with usage data
+const AdminInsightsProjectName = "Admin Insights"
+
+// names of the datasources published in the Admin Insights project
+const AdminInsightsTSEvents = "TS Events"
+const AdminInsightsTSUsers = "TS Users"
+
+// https://help.tableau.com/current/online/en-us/adminview_insights.htm
+func (api *API) GetAdminInsightsProject(siteId string) (Project, error) {
+	return api.GetAdminInsightsProjectContext(context.Background(), siteId)
+}
+
+func (api *API) GetAdminInsightsProjectContext(ctx context.Context, siteId string) (Project, error) {
+	return api.GetProjectByNameContext(ctx, siteId, AdminInsightsProjectName)
+}
+
+// returns every datasource published in the Admin Insights project of the site
+func (api *API) QueryAdminInsightsDatasources(siteId string) ([]Datasource, error) {
+	return api.QueryAdminInsightsDatasourcesContext(context.Background(), siteId)
+}
+
+func (api *API) QueryAdminInsightsDatasourcesContext(ctx context.Context, siteId string) ([]Datasource, error) {
+	return api.queryAdminInsightsDatasources(ctx, siteId)
+}
+
+// downloads one of the Admin Insights datasources (e.g. AdminInsightsTSEvents) as a .tdsx including its extract
+// and streams it into w, so the usage data can be loaded into an external warehouse. Returns the number of bytes
+// written. The REST API serves datasources only as files, there is no CSV export of their data.
+func (api *API) DownloadAdminInsightsDatasource(siteId, datasourceName string, w io.Writer) (int64, error) {
+	return api.DownloadAdminInsightsDatasourceContext(context.Background(), siteId, datasourceName, w)
+}
+
+func (api *API) DownloadAdminInsightsDatasourceContext(ctx context.Context, siteId, datasourceName string, w io.Writer) (int64, error) {
+	datasources, err := api.queryAdminInsightsDatasources(ctx, siteId, Filter().Eq("name", datasourceName))
+	if err != nil {
+		return 0, err
+	}
+	for _, datasource := range datasources {
+		if datasource.Name == datasourceName {
+			return api.DownloadDatasourceToContext(ctx, siteId, datasource.ID, true, w)
+		}
+	}
+	return 0, fmt.Errorf("Admin Insights datasource named '%s' %w", datasourceName, ErrNotFound)
+}
+
+// the datasources of the top level Admin Insights project matching opts, filtered on the server by project name
+func (api *API) queryAdminInsightsDatasources(ctx context.Context, siteId string, opts ...QueryOption) ([]Datasource, error) {
+	project, err := api.GetAdminInsightsProjectContext(ctx, siteId)
+	if err != nil {
+		return nil, err
+	}
+	opts = append([]QueryOption{Filter().Eq("projectName", project.Name)}, opts...)
+	datasources, err := api.QueryAllDatasourcesContext(ctx, siteId, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// nested projects may share the name
+	insights := []Datasource{}
+	for _, datasource := range datasources {
+		if datasource.Project != nil && datasource.Project.ID == project.ID {
+			insights = append(insights, datasource)
+		}
+	}
+	api.loggerFor(ctx).Debugf("Found %d Admin Insights datasources for siteId %s", len(insights), siteId)
+	return insights, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker is open for the server
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitBreaker opens after a number of consecutive failures to a host: connection errors, timeouts and 5xx
+// responses. While open requests to the host fail with ErrCircuitOpen. Once the cool-down has passed requests
+// are let through again, the first failure opens the circuit for another cool-down and a success closes it.
+type CircuitBreaker struct {
+	// consecutive failures that open the circuit
+	FailureThreshold int
+	// how long requests fail fast once the circuit opened
+	CoolDown time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+// DefaultCircuitBreakerThreshold and DefaultCircuitBreakerCoolDown apply when NewCircuitBreaker is given zero values
+const DefaultCircuitBreakerThreshold = 5
+const DefaultCircuitBreakerCoolDown = 30 * time.Second
+
+// e.g. api.CircuitBreaker = NewCircuitBreaker(5, time.Minute)
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultCircuitBreakerThreshold
+	}
+	if coolDown <= 0 {
+		coolDown = DefaultCircuitBreakerCoolDown
+	}
+	return &CircuitBreaker{FailureThreshold: failureThreshold, CoolDown: coolDown}
+}
+
+// whether the circuit for the host is open, and until when
+func (b *CircuitBreaker) Open(host string) (bool, time.Time) {
+	if b == nil {
+		return false, time.Time{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.hosts[host]; ok && time.Now().Before(c.openUntil) {
+		return true, c.openUntil
+	}
+	return false, time.Time{}
+}
+
+// closes the circuit for every host
+func (b *CircuitBreaker) Reset() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.hosts = nil
+}
+
+func (b *CircuitBreaker) allow(host string) error {
+	if open, until := b.Open(host); open {
+		return fmt.Errorf("%w: %s until %s", ErrCircuitOpen, host, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// records the outcome of a request, returns true when the failure opened the circuit
+func (b *CircuitBreaker) record(host string, failed bool) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		delete(b.hosts, host)
+		return false
+	}
+	if b.hosts == nil {
+		b.hosts = map[string]*circuit{}
+	}
+	c, ok := b.hosts[host]
+	if !ok {
+		c = &circuit{}
+		b.hosts[host] = c
+	}
+	c.failures++
+	if c.failures < b.FailureThreshold {
+		return false
+	}
+	c.openUntil = time.Now().Add(b.CoolDown)
+	return true
+}
+
+// server side and network failures count, client errors such as a 404 or an expired session don't
+func breakerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	// the TableauError of every error response turns into a StatusError
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= 500
+	}
+	return isRetryable(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (api *API) doRequestThroughBreaker(ctx context.Context, requestUrl string, method string, payload []byte, upload *uploadBody, result interface{}, headers map[string]string) ([]byte, error) {
+	if api.CircuitBreaker == nil {
+		return api.doRequest(ctx, requestUrl, method, payload, upload, result, headers)
+	}
+	host := requestUrl
+	if parsed, err := url.Parse(requestUrl); err == nil {
+		host = parsed.Host
+	}
+	if err := api.CircuitBreaker.allow(host); err != nil {
+		return nil, err
+	}
+	body, err := api.doRequest(ctx, requestUrl, method, payload, upload, result, headers)
+	if api.CircuitBreaker.record(host, breakerFailure(err)) {
+		api.loggerFor(ctx).Errorf("t4g circuit breaker open for %s after %d consecutive failures, last:%v", host, api.CircuitBreaker.FailureThreshold, err)
+	}
+	return body, err
+}
@@ -0,0 +1,101 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBreakerFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"success", nil, false},
+		{"server error", &TableauError{StatusCode: 500, Code: "500000"}, true},
+		{"unavailable without a body", &RequestError{RequestID: "1", Err: &TableauError{StatusCode: 503}}, true},
+		{"not found", &TableauError{StatusCode: 404, Code: "404004"}, false},
+		{"session expired", &TableauError{StatusCode: 401, Code: tokenExpiredErrorCode}, false},
+		{"canceled", fmt.Errorf("sending: %w", context.Canceled), false},
+		{"deadline", fmt.Errorf("sending: %w", context.DeadlineExceeded), true},
+	}
+	for _, test := range tests {
+		if got := breakerFailure(test.err); got != test.want {
+			t.Errorf("%s: breakerFailure(%v) = %v, want %v", test.name, test.err, got, test.want)
+		}
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	coolDown := 50 * time.Millisecond
+	breaker := NewCircuitBreaker(2, coolDown)
+	host := "tableau"
+	allowed := func(want bool) {
+		t.Helper()
+		err := breaker.allow(host)
+		if (err == nil) != want {
+			t.Fatalf("allow returned %v, want allowed %v", err, want)
+		}
+		if err != nil && !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("allow returned %v, want ErrCircuitOpen", err)
+		}
+	}
+
+	// closed: failures below the threshold and a success resetting the count
+	allowed(true)
+	if breaker.record(host, true) {
+		t.Fatal("the first failure opened the circuit")
+	}
+	breaker.record(host, false)
+	if breaker.record(host, true) {
+		t.Fatal("a failure after a success opened the circuit")
+	}
+	allowed(true)
+
+	// open: the threshold is reached
+	if !breaker.record(host, true) {
+		t.Fatal("the second consecutive failure didn't open the circuit")
+	}
+	allowed(false)
+	if open, _ := breaker.Open("other"); open {
+		t.Fatal("the circuit opened for another host")
+	}
+
+	// half-open: the first failure after the cool-down opens it again
+	time.Sleep(coolDown)
+	allowed(true)
+	if !breaker.record(host, true) {
+		t.Fatal("a failure after the cool-down didn't open the circuit again")
+	}
+	allowed(false)
+
+	// half-open: a success after the cool-down closes it
+	time.Sleep(coolDown)
+	allowed(true)
+	if breaker.record(host, false) {
+		t.Fatal("a success opened the circuit")
+	}
+	if breaker.record(host, true) {
+		t.Fatal("a single failure after closing opened the circuit")
+	}
+	allowed(true)
+
+	breaker.record(host, true)
+	allowed(false)
+	breaker.Reset()
+	allowed(true)
+}
@@ -0,0 +1,189 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tableau Bridge is only available on Tableau Cloud
+
+func (api *API) QueryBridgeClients(siteId string) ([]BridgeClient, error) {
+	return api.QueryBridgeClientsContext(context.Background(), siteId)
+}
+
+func (api *API) QueryBridgeClientsContext(ctx context.Context, siteId string) ([]BridgeClient, error) {
+	totalAvailable := 1
+	clients := []BridgeClient{}
+	for i := 1; len(clients) < totalAvailable; i++ {
+		requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/clients?pageSize=%v&pageNumber=%v", api.Server, api.Version, siteId, PAGESIZE, i)
+		headers := make(map[string]string)
+		response := QueryBridgeClientsResponse{}
+		if err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers); err != nil {
+			return clients, err
+		}
+		clients = append(clients, response.BridgeClients.BridgeClients...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return clients, nil
+}
+
+func (api *API) QueryBridgeClient(siteId, clientId string) (BridgeClient, error) {
+	return api.QueryBridgeClientContext(context.Background(), siteId, clientId)
+}
+
+func (api *API) QueryBridgeClientContext(ctx context.Context, siteId, clientId string) (BridgeClient, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/clients/%s", api.Server, api.Version, siteId, clientId)
+	headers := make(map[string]string)
+	retval := QueryBridgeClientResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.BridgeClient, err
+}
+
+func (api *API) UpdateBridgeClient(siteId, clientId string, update BridgeClientUpdate) (BridgeClient, error) {
+	return api.UpdateBridgeClientContext(context.Background(), siteId, clientId, update)
+}
+
+func (api *API) UpdateBridgeClientContext(ctx context.Context, siteId, clientId string, update BridgeClientUpdate) (BridgeClient, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/clients/%s", api.Server, api.Version, siteId, clientId)
+	payload, headers, err := api.encodeRequest(UpdateBridgeClientRequest{Request: update})
+	if err != nil {
+		return BridgeClient{}, err
+	}
+	retval := QueryBridgeClientResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.BridgeClient, err
+}
+
+// hands the client over to another site user, e.g. when its owner leaves the company
+func (api *API) ReassignBridgeClientOwner(siteId, clientId, ownerId string) (BridgeClient, error) {
+	return api.ReassignBridgeClientOwnerContext(context.Background(), siteId, clientId, ownerId)
+}
+
+func (api *API) ReassignBridgeClientOwnerContext(ctx context.Context, siteId, clientId, ownerId string) (BridgeClient, error) {
+	return api.UpdateBridgeClientContext(ctx, siteId, clientId, BridgeClientUpdate{Owner: &User{ID: ownerId}})
+}
+
+func (api *API) DeleteBridgeClient(siteId, clientId string) error {
+	return api.DeleteBridgeClientContext(context.Background(), siteId, clientId)
+}
+
+func (api *API) DeleteBridgeClientContext(ctx context.Context, siteId, clientId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/clients/%s", api.Server, api.Version, siteId, clientId)
+	return api.delete(ctx, requestUrl)
+}
+
+// the status of a bridge client the server currently has a connection from
+const BridgeClientStatusConnected = "Connected"
+
+// deletes the clients that haven't connected for longer than disconnectedFor and returns them. Clients that
+// are connected or never reported a connection time are left alone.
+func (api *API) DeleteStaleBridgeClients(siteId string, disconnectedFor time.Duration) ([]BridgeClient, error) {
+	return api.DeleteStaleBridgeClientsContext(context.Background(), siteId, disconnectedFor)
+}
+
+func (api *API) DeleteStaleBridgeClientsContext(ctx context.Context, siteId string, disconnectedFor time.Duration) ([]BridgeClient, error) {
+	if disconnectedFor <= 0 {
+		return nil, errors.New("disconnectedFor must be positive, or every client would be deleted")
+	}
+	clients, err := api.QueryBridgeClientsContext(ctx, siteId)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-disconnectedFor)
+	deleted := []BridgeClient{}
+	for _, client := range clients {
+		if strings.EqualFold(client.Status, BridgeClientStatusConnected) {
+			continue
+		}
+		lastConnected, err := time.Parse(time.RFC3339, client.LastConnected)
+		if err != nil || lastConnected.After(cutoff) {
+			continue
+		}
+		if err = api.DeleteBridgeClientContext(ctx, siteId, client.ID); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, client)
+	}
+	return deleted, nil
+}
+
+func (api *API) QueryBridgePools(siteId string) ([]BridgePool, error) {
+	return api.QueryBridgePoolsContext(context.Background(), siteId)
+}
+
+func (api *API) QueryBridgePoolsContext(ctx context.Context, siteId string) ([]BridgePool, error) {
+	totalAvailable := 1
+	pools := []BridgePool{}
+	for i := 1; len(pools) < totalAvailable; i++ {
+		requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/pools?pageSize=%v&pageNumber=%v", api.Server, api.Version, siteId, PAGESIZE, i)
+		headers := make(map[string]string)
+		response := QueryBridgePoolsResponse{}
+		if err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers); err != nil {
+			return pools, err
+		}
+		pools = append(pools, response.BridgePools.BridgePools...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return pools, nil
+}
+
+func (api *API) CreateBridgePool(siteId string, pool BridgePool) (BridgePool, error) {
+	return api.CreateBridgePoolContext(context.Background(), siteId, pool)
+}
+
+func (api *API) CreateBridgePoolContext(ctx context.Context, siteId string, pool BridgePool) (BridgePool, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/pools", api.Server, api.Version, siteId)
+	payload, headers, err := api.encodeRequest(CreateBridgePoolRequest{Request: pool})
+	if err != nil {
+		return BridgePool{}, err
+	}
+	retval := CreateBridgePoolResponse{}
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	return retval.BridgePool, err
+}
+
+func (api *API) DeleteBridgePool(siteId, poolId string) error {
+	return api.DeleteBridgePoolContext(context.Background(), siteId, poolId)
+}
+
+func (api *API) DeleteBridgePoolContext(ctx context.Context, siteId, poolId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/pools/%s", api.Server, api.Version, siteId, poolId)
+	return api.delete(ctx, requestUrl)
+}
+
+// a client belongs to at most one pool, assigning it moves it out of its current pool
+func (api *API) AssignBridgeClientToPool(siteId, poolId, clientId string) error {
+	return api.AssignBridgeClientToPoolContext(context.Background(), siteId, poolId, clientId)
+}
+
+func (api *API) AssignBridgeClientToPoolContext(ctx context.Context, siteId, poolId, clientId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/pools/%s/clients/%s", api.Server, api.Version, siteId, poolId, clientId)
+	payload, headers, err := api.encodeRequest(struct{}{})
+	if err != nil {
+		return err
+	}
+	return api.makeRequest(ctx, requestUrl, PUT, payload, nil, headers)
+}
+
+func (api *API) RemoveBridgeClientFromPool(siteId, poolId, clientId string) error {
+	return api.RemoveBridgeClientFromPoolContext(context.Background(), siteId, poolId, clientId)
+}
+
+func (api *API) RemoveBridgeClientFromPoolContext(ctx context.Context, siteId, poolId, clientId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/bridge/pools/%s/clients/%s", api.Server, api.Version, siteId, poolId, clientId)
+	return api.delete(ctx, requestUrl)
+}
@@ -0,0 +1,60 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteStaleBridgeClients(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	clients := `<bridgeClient id="stale" status="Disconnected" lastConnected="` + old + `"/>` +
+		`<bridgeClient id="recent" status="Disconnected" lastConnected="` + recent + `"/>` +
+		`<bridgeClient id="connected" status="Connected" lastConnected="` + old + `"/>` +
+		`<bridgeClient id="never" status="Disconnected"/>`
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="4"/><bridgeClients>%s</bridgeClients></tsResponse>`, clients)
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+
+	for _, disconnectedFor := range []time.Duration{0, -time.Hour} {
+		if _, err := api.DeleteStaleBridgeClients("site", disconnectedFor); err == nil {
+			t.Errorf("DeleteStaleBridgeClients(%v) succeeded", disconnectedFor)
+		}
+	}
+	if len(deleted) > 0 {
+		t.Fatalf("an invalid duration deleted %v", deleted)
+	}
+
+	returned, err := api.DeleteStaleBridgeClients("site", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deleted, []string{"stale"}) || len(returned) != 1 || returned[0].ID != "stale" {
+		t.Fatalf("deleted %v and returned %+v, want only the stale client", deleted, returned)
+	}
+}
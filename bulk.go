@@ -0,0 +1,144 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultBulkConcurrency applies when a BulkExecutor's Concurrency is zero, Tableau Server throttles
+// clients that send many more requests at once
+const DefaultBulkConcurrency = 4
+
+// BulkExecutor runs an API call for many items with a bounded number of workers and collects the outcome of
+// every item, e.g. deleting datasources:
+//
+//	report := NewBulkExecutor(8).Run(ctx, datasourceIds, func(ctx context.Context, id string) (interface{}, error) {
+//		return nil, api.DeleteDatasourceContext(ctx, siteId, id)
+//	})
+//	if err := report.Err(); err != nil {
+type BulkExecutor struct {
+	Concurrency int
+	// cancels the items not started yet after the first failure
+	StopOnError bool
+	// called from the workers as each item finishes
+	OnResult func(BulkResult)
+}
+
+func NewBulkExecutor(concurrency int) *BulkExecutor {
+	return &BulkExecutor{Concurrency: concurrency}
+}
+
+// BulkResult is the outcome of one item, Value is what the call returned
+type BulkResult struct {
+	Key      string
+	Value    interface{}
+	Err      error
+	Duration time.Duration
+}
+
+// BulkReport holds a result for every item in the order of the keys
+type BulkReport struct {
+	Results   []BulkResult
+	Succeeded int
+	Failed    int
+	Duration  time.Duration
+}
+
+// the results of the items that failed
+func (r BulkReport) Failures() []BulkResult {
+	failures := []BulkResult{}
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// nil when every item succeeded, a *BulkError otherwise
+func (r BulkReport) Err() error {
+	if r.Failed == 0 {
+		return nil
+	}
+	return &BulkError{Total: len(r.Results), Failures: r.Failures()}
+}
+
+type BulkError struct {
+	Total    int
+	Failures []BulkResult
+}
+
+func (e *BulkError) Error() string {
+	first := e.Failures[0]
+	return fmt.Sprintf("%d of %d items failed, first %s: %v", len(e.Failures), e.Total, first.Key, first.Err)
+}
+
+// unwraps to the first failure, so errors.Is and errors.As see e.g. a TError
+func (e *BulkError) Unwrap() error {
+	return e.Failures[0].Err
+}
+
+// calls fn for every key. Items that haven't started when ctx is done, or after a failure with StopOnError,
+// fail with the context's error without calling fn.
+func (e *BulkExecutor) Run(ctx context.Context, keys []string, fn func(ctx context.Context, key string) (interface{}, error)) BulkReport {
+	start := time.Now()
+	concurrency := e.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	report := BulkReport{Results: make([]BulkResult, len(keys))}
+	indexes := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(keys); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				result := BulkResult{Key: keys[index]}
+				if result.Err = ctx.Err(); result.Err == nil {
+					itemStart := time.Now()
+					result.Value, result.Err = fn(ctx, keys[index])
+					result.Duration = time.Since(itemStart)
+				}
+				mu.Lock()
+				report.Results[index] = result
+				if result.Err != nil {
+					report.Failed++
+					if e.StopOnError {
+						cancel()
+					}
+				} else {
+					report.Succeeded++
+				}
+				mu.Unlock()
+				if e.OnResult != nil {
+					e.OnResult(result)
+				}
+			}
+		}()
+	}
+	for index := range keys {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	report.Duration = time.Since(start)
+	return report
+}
@@ -0,0 +1,163 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const etagHeader = "ETag"
+const lastModifiedHeader = "Last-Modified"
+const ifNoneMatchHeader = "If-None-Match"
+const ifModifiedSinceHeader = "If-Modified-Since"
+
+// CachedResponse is the body of a GET response and the validators to revalidate it with
+type CachedResponse struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	Stored       time.Time
+}
+
+// Cache stores GET responses, keys are made of the request url and the session so users never see each
+// other's responses. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	// drops the responses whose key starts with prefix, called with a session's prefix after it changes content
+	DeletePrefix(prefix string)
+}
+
+// DefaultCacheEntries bounds a MemoryCache created with no limit
+const DefaultCacheEntries = 1000
+
+// MemoryCache is a Cache that evicts the least recently stored response once full
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*CachedResponse
+	order      []string
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+	return &MemoryCache{maxEntries: maxEntries, entries: map[string]*CachedResponse{}}
+}
+
+func (c *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	response, ok := c.entries[key]
+	return response, ok
+}
+
+func (c *MemoryCache) Set(key string, response *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		for i, stored := range c.order {
+			if stored == key {
+				c.order = append(c.order[:i], c.order[i+1:]...)
+				break
+			}
+		}
+	}
+	c.entries[key] = response
+	c.order = append(c.order, key)
+	for len(c.order) > c.maxEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+func (c *MemoryCache) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := c.order[:0]
+	for _, key := range c.order {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		} else {
+			kept = append(kept, key)
+		}
+	}
+	c.order = kept
+}
+
+// drops every cached response, e.g. after changing content the cached listings include
+func (c *MemoryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*CachedResponse{}
+	c.order = nil
+}
+
+// the cache key and response for a cacheable request. Only responses decoded into a result are cached,
+// file downloads may be gigabytes.
+func (api *API) cachedResponse(ctx context.Context, method, requestUrl string, result interface{}) (string, *CachedResponse) {
+	if api.Cache == nil || method != GET || result == nil {
+		return "", nil
+	}
+	if _, ok := result.(*streamTo); ok {
+		return "", nil
+	}
+	if op, _ := ctx.Value(operationKey{}).(operation); op == operationDownload {
+		return "", nil
+	}
+	key := api.cacheSessionKey() + requestUrl
+	cached, _ := api.Cache.Get(key)
+	return key, cached
+}
+
+// the prefix of the session's cache keys, the token is hashed so a shared cache never holds it
+func (api *API) cacheSessionKey() string {
+	session := sha256.Sum256([]byte(api.Token()))
+	return hex.EncodeToString(session[:8]) + " "
+}
+
+// drops the session's cached responses once a request may have changed content they list
+func (api *API) invalidateCache() {
+	if api.Cache != nil {
+		api.Cache.DeletePrefix(api.cacheSessionKey())
+	}
+}
+
+// whether a cached response can be used without asking the server
+func (response *CachedResponse) fresh(ttl time.Duration) bool {
+	return ttl > 0 && time.Since(response.Stored) < ttl
+}
+
+func (response *CachedResponse) setConditionalHeaders(req *http.Request) {
+	if response.ETag != "" {
+		req.Header.Set(ifNoneMatchHeader, response.ETag)
+	}
+	if response.LastModified != "" {
+		req.Header.Set(ifModifiedSinceHeader, response.LastModified)
+	}
+}
+
+// stores a successful response that can be revalidated or is kept for the TTL
+func (api *API) cacheResponse(key string, resp *http.Response, body []byte) {
+	response := &CachedResponse{Body: body, ETag: resp.Header.Get(etagHeader), LastModified: resp.Header.Get(lastModifiedHeader), Stored: time.Now()}
+	if response.ETag == "" && response.LastModified == "" && api.CacheTTL <= 0 {
+		return
+	}
+	api.Cache.Set(key, response)
+}
@@ -0,0 +1,99 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serves the site's projects with an ETag that changes with every project created
+type projectsServer struct {
+	projects    []string
+	notModified int
+	conditional []string
+	requests    int
+}
+
+func (s *projectsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests++
+	w.Header().Set("Content-Type", "application/xml")
+	etag := fmt.Sprintf("\"%d\"", len(s.projects))
+	if r.Method == http.MethodPost {
+		s.projects = append(s.projects, fmt.Sprintf("p%d", len(s.projects)+1))
+		fmt.Fprintf(w, `<tsResponse><project id="%s" name="%s"/></tsResponse>`, s.projects[len(s.projects)-1], s.projects[len(s.projects)-1])
+		return
+	}
+	s.conditional = append(s.conditional, r.Header.Get(ifNoneMatchHeader))
+	if r.Header.Get(ifNoneMatchHeader) == etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set(etagHeader, etag)
+	projects := ""
+	for _, id := range s.projects {
+		projects += fmt.Sprintf(`<project id="%s" name="%s"/>`, id, id)
+	}
+	fmt.Fprintf(w, `<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="%d"/><projects>%s</projects></tsResponse>`, len(s.projects), projects)
+}
+
+func TestCacheRevalidation(t *testing.T) {
+	projects := &projectsServer{projects: []string{"p1"}}
+	server := httptest.NewServer(projects)
+	defer server.Close()
+	api := NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+	api.Cache = NewMemoryCache(10)
+
+	for i := 0; i < 2; i++ {
+		listed, err := api.QueryProjects("site")
+		if err != nil || len(listed) != 1 || listed[0].ID != "p1" {
+			t.Fatalf("QueryProjects %d returned %+v, %v", i, listed, err)
+		}
+	}
+	if projects.notModified != 1 {
+		t.Fatalf("the server answered %d times with 304, want once", projects.notModified)
+	}
+
+	if _, err := api.CreateProject("site", Project{Name: "p2"}); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := api.QueryProjects("site")
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("QueryProjects after CreateProject returned %+v, %v", listed, err)
+	}
+	if want := []string{"", "\"1\"", ""}; strings.Join(projects.conditional, "|") != strings.Join(want, "|") {
+		t.Fatalf("listings were revalidated with %q, want %q", projects.conditional, want)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	projects := &projectsServer{projects: []string{"p1"}}
+	server := httptest.NewServer(projects)
+	defer server.Close()
+	api := NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+	api.Cache = NewMemoryCache(10)
+	api.CacheTTL = time.Minute
+
+	for i := 0; i < 3; i++ {
+		if listed, err := api.QueryProjects("site"); err != nil || len(listed) != 1 {
+			t.Fatalf("QueryProjects %d returned %+v, %v", i, listed, err)
+		}
+	}
+	if projects.requests != 1 {
+		t.Fatalf("made %d requests within the TTL, want 1", projects.requests)
+	}
+}
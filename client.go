@@ -14,7 +14,7 @@ package tableau4go
 import (
 	"archive/zip"
 	"bytes"
-	"encoding/xml"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -23,24 +23,74 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const contentTypeHeader = "Content-Type"
 const contentLengthHeader = "Content-Length"
 const authHeader = "X-Tableau-Auth"
+const userAgentHeader = "User-Agent"
+
+// sent when API.UserAgent is empty
+const DefaultUserAgent = "tableau4go"
 const applicationXmlContentType = "application/xml"
+const applicationJsonContentType = "application/json"
+const acceptHeader = "Accept"
 const POST = "POST"
 const GET = "GET"
 const DELETE = "DELETE"
+const PUT = "PUT"
 const PAGESIZE = 100
 
+var errNoCredentialsInResponse = errors.New("the sign in response contained no credentials")
+
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Sign_In%3FTocPath%3DAPI%2520Reference%7C_____51
 func (api *API) Signin(username, password string, contentUrl string, userIdToImpersonate string) error {
-	requestUrl := fmt.Sprintf("%s/api/%s/auth/signin", api.Server, api.Version)
+	return api.SigninContext(context.Background(), username, password, contentUrl, userIdToImpersonate)
+}
+
+func (api *API) SigninContext(ctx context.Context, username, password string, contentUrl string, userIdToImpersonate string) error {
 	credentials := Credentials{Name: username, Password: password}
 	if len(userIdToImpersonate) > 0 {
 		credentials.Impersonate = &User{ID: userIdToImpersonate}
 	}
+	return api.signin(ctx, credentials, contentUrl)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_concepts_auth.htm#sign-in-with-jwt
+// signs in with a JSON Web Token issued for a Tableau Connected App, see NewConnectedAppJWT
+func (api *API) SigninWithJWT(jwt string, contentUrl string) error {
+	return api.SigninWithJWTContext(context.Background(), jwt, contentUrl)
+}
+
+func (api *API) SigninWithJWTContext(ctx context.Context, jwt string, contentUrl string) error {
+	return api.signin(ctx, Credentials{JWT: jwt}, contentUrl)
+}
+
+func (api *API) signin(ctx context.Context, credentials Credentials, contentUrl string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/auth/signin", api.Server, api.Version)
+	credentials.Site = &Site{ContentUrl: api.signinSiteName(contentUrl)}
+	payload, headers, err := api.encodeRequest(SigninRequest{Request: credentials})
+	if err != nil {
+		return err
+	}
+	retval := AuthResponse{}
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	if err == nil && retval.Credentials == nil {
+		err = errNoCredentialsInResponse
+	}
+	if err == nil {
+		// a JWT can only be used once, those sessions are renewed through api.Reauthenticate
+		var replayable *Credentials
+		if credentials.JWT == "" {
+			replayable = &credentials
+		}
+		api.setSignin(retval.Credentials, replayable, contentUrl)
+	}
+	return err
+}
+
+func (api *API) signinSiteName(contentUrl string) string {
 	siteName := contentUrl
 	// this seems to have changed. If you are looking for the default site, you must pass
 	// blank
@@ -49,29 +99,46 @@ func (api *API) Signin(username, password string, contentUrl string, userIdToImp
 			siteName = ""
 		}
 	}
-	credentials.Site = &Site{ContentUrl: siteName}
-	request := SigninRequest{Request: credentials}
-	signInXML, err := request.XML()
+	return siteName
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_authentication.htm#switch_site
+// moves the current session to another site the user has access to without signing in again
+func (api *API) SwitchSite(contentUrl string) error {
+	return api.SwitchSiteContext(context.Background(), contentUrl)
+}
+
+func (api *API) SwitchSiteContext(ctx context.Context, contentUrl string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/auth/switchSite", api.Server, api.Version)
+	payload, headers, err := api.encodeRequest(SwitchSiteRequest{Request: Site{ContentUrl: api.signinSiteName(contentUrl)}})
 	if err != nil {
 		return err
 	}
-	payload := string(signInXML)
-	headers := make(map[string]string)
-	headers[contentTypeHeader] = applicationXmlContentType
 	retval := AuthResponse{}
-	err = api.makeRequest(requestUrl, POST, []byte(payload), &retval, headers)
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	if err == nil && retval.Credentials == nil {
+		err = errNoCredentialsInResponse
+	}
 	if err == nil {
-		api.AuthToken = retval.Credentials.Token
+		// re-authentication has to land on the site we switched to
+		api.setSite(retval.Credentials, contentUrl)
 	}
 	return err
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Sign_Out%3FTocPath%3DAPI%2520Reference%7C_____52
 func (api *API) Signout() error {
+	return api.SignoutContext(context.Background())
+}
+
+func (api *API) SignoutContext(ctx context.Context) error {
 	requestUrl := fmt.Sprintf("%s/api/%s/auth/signout", api.Server, api.Version)
 	headers := make(map[string]string)
-	headers[contentTypeHeader] = applicationXmlContentType
-	err := api.makeRequest(requestUrl, POST, nil, nil, headers)
+	headers[contentTypeHeader] = api.codec().ContentType()
+	err := api.makeRequest(ctx, requestUrl, POST, nil, nil, headers)
+	if err == nil {
+		api.clearSignin()
+	}
 	return err
 }
 
@@ -82,73 +149,108 @@ func ConvertSiteNameToContentUrl(siteName string) string {
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Server_Info%3FTocPath%3DAPI%2520Reference%7C__
 func (api *API) ServerInfo() (ServerInfo, error) {
+	return api.ServerInfoContext(context.Background())
+}
+
+func (api *API) ServerInfoContext(ctx context.Context) (ServerInfo, error) {
 	// this call only works on apiVersion 2.4 and up
 	requestUrl := fmt.Sprintf("%s/api/%s/serverinfo", api.Server, "2.4")
 	headers := make(map[string]string)
 	retval := ServerInfoResponse{}
-	err := api.makeRequest(requestUrl, GET, nil, &retval, headers)
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
 	return retval.ServerInfo, err
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Sites%3FTocPath%3DAPI%2520Reference%7C_____40
 func (api *API) QuerySites() ([]Site, error) {
+	return api.QuerySitesContext(context.Background())
+}
+
+func (api *API) QuerySitesContext(ctx context.Context) ([]Site, error) {
 	requestUrl := fmt.Sprintf("%s/api/%s/sites/", api.Server, api.Version)
 	headers := make(map[string]string)
 	retval := QuerySitesResponse{}
-	err := api.makeRequest(requestUrl, GET, nil, &retval, headers)
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
 	return retval.Sites.Sites, err
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Sites%3FTocPath%3DAPI%2520Reference%7C_____40
 func (api *API) QuerySite(siteID string, includeStorage bool) (Site, error) {
-	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, siteID)
-	if includeStorage {
-		requestUrl += fmt.Sprintf("?includeStorage=%v", includeStorage)
-	}
-	return api.executeQuerySite(requestUrl)
+	return api.QuerySiteContext(context.Background(), siteID, includeStorage)
+}
+
+func (api *API) QuerySiteContext(ctx context.Context, siteID string, includeStorage bool) (Site, error) {
+	return api.querySite(ctx, SiteKeyID, siteID, includeStorage)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Sites%3FTocPath%3DAPI%2520Reference%7C_____40
 func (api *API) QuerySiteByName(name string, includeStorage bool) (Site, error) {
-	return api.querySiteByKey("name", name, includeStorage)
+	return api.QuerySiteByNameContext(context.Background(), name, includeStorage)
+}
+
+func (api *API) QuerySiteByNameContext(ctx context.Context, name string, includeStorage bool) (Site, error) {
+	return api.querySite(ctx, SiteKeyName, name, includeStorage)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Sites%3FTocPath%3DAPI%2520Reference%7C_____40
 func (api *API) QuerySiteByContentUrl(contentUrl string, includeStorage bool) (Site, error) {
-	return api.querySiteByKey("contentUrl", contentUrl, includeStorage)
+	return api.QuerySiteByContentUrlContext(context.Background(), contentUrl, includeStorage)
 }
 
-// http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Sites%3FTocPath%3DAPI%2520Reference%7C_____40
-func (api *API) querySiteByKey(key, value string, includeStorage bool) (Site, error) {
-	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s?key=%s", api.Server, api.Version, value, key)
+func (api *API) QuerySiteByContentUrlContext(ctx context.Context, contentUrl string, includeStorage bool) (Site, error) {
+	return api.querySite(ctx, SiteKeyContentURL, contentUrl, includeStorage)
+}
+
+// finds a site by its id, name or content url, e.g. LookupSite(SiteKeyName, "Finance & Ops")
+func (api *API) LookupSite(key SiteKey, value string) (Site, error) {
+	return api.LookupSiteContext(context.Background(), key, value)
+}
+
+func (api *API) LookupSiteContext(ctx context.Context, key SiteKey, value string) (Site, error) {
+	return api.querySite(ctx, key, value, false)
+}
+
+func (api *API) querySite(ctx context.Context, key SiteKey, value string, includeStorage bool) (Site, error) {
+	requestUrl, err := api.siteUrl(key, value)
+	if err != nil {
+		return Site{}, err
+	}
 	if includeStorage {
-		requestUrl += fmt.Sprintf("&includeStorage=%v", includeStorage)
+		requestUrl += fmt.Sprintf("%sincludeStorage=%v", querySeparator(requestUrl), includeStorage)
 	}
-	return api.executeQuerySite(requestUrl)
+	return api.executeQuerySite(ctx, requestUrl)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Sites%3FTocPath%3DAPI%2520Reference%7C_____40
-func (api *API) executeQuerySite(requestUrl string) (Site, error) {
+func (api *API) executeQuerySite(ctx context.Context, requestUrl string) (Site, error) {
 	headers := make(map[string]string)
 	retval := QuerySiteResponse{}
-	err := api.makeRequest(requestUrl, GET, nil, &retval, headers)
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
 	return retval.Site, err
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_User_On_Site%3FTocPath%3DAPI%2520Reference%7C_____47
 func (api *API) QueryUserOnSite(siteId, userId string) (User, error) {
+	return api.QueryUserOnSiteContext(context.Background(), siteId, userId)
+}
+
+func (api *API) QueryUserOnSiteContext(ctx context.Context, siteId, userId string) (User, error) {
 	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/users/%s", api.Server, api.Version, siteId, userId)
 	headers := make(map[string]string)
 	retval := QueryUserOnSiteResponse{}
-	err := api.makeRequest(requestUrl, GET, nil, &retval, headers)
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
 	return retval.User, err
 }
 
-func (api *API) QueryProjects(siteId string) ([]Project, error) {
+func (api *API) QueryProjects(siteId string, opts ...QueryOption) ([]Project, error) {
+	return api.QueryProjectsContext(context.Background(), siteId, opts...)
+}
+
+func (api *API) QueryProjectsContext(ctx context.Context, siteId string, opts ...QueryOption) ([]Project, error) {
 	totalAvailable := 1
 	projects := []Project{}
 	for i := 1; len(projects) < totalAvailable; i++ {
-		projectsResponse, err := api.QueryProjectsByPage(siteId, i)
+		projectsResponse, err := api.QueryProjectsByPageContext(ctx, siteId, i, opts...)
 		if err != nil {
 			return projects, err
 		}
@@ -161,29 +263,65 @@ func (api *API) QueryProjects(siteId string) ([]Project, error) {
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Projects%3FTocPath%3DAPI%2520Reference%7C_____38
-func (api *API) QueryProjectsByPage(siteId string, pageNum int) (QueryProjectsResponse, error) {
-	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/projects?pageSize=%v&pageNumber=%v", api.Server, api.Version, siteId, PAGESIZE, pageNum)
+func (api *API) QueryProjectsByPage(siteId string, pageNum int, opts ...QueryOption) (QueryProjectsResponse, error) {
+	return api.QueryProjectsByPageContext(context.Background(), siteId, pageNum, opts...)
+}
+
+func (api *API) QueryProjectsByPageContext(ctx context.Context, siteId string, pageNum int, opts ...QueryOption) (QueryProjectsResponse, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/projects", api.Server, api.Version, siteId), pageNum, opts)
 	headers := make(map[string]string)
 	response := QueryProjectsResponse{}
-	err := api.makeRequest(requestUrl, GET, nil, &response, headers)
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
 	return response, err
 }
 
-func (api *API) GetProjectByName(siteId, name string) (Project, error) {
-	projects, err := api.QueryProjects(siteId)
+// ErrAmbiguousProject is returned by GetProjectByName when nested projects share the name and no parent was given
+var ErrAmbiguousProject = errors.New("several projects have this name, pass the parent project id")
+
+// names are only unique among the children of a project, pass parentProjectId to pick the project under that parent,
+// an empty parent id selects the top level project. Without a parent a top level project wins over nested ones.
+func (api *API) GetProjectByName(siteId, name string, parentProjectId ...string) (Project, error) {
+	return api.GetProjectByNameContext(context.Background(), siteId, name, parentProjectId...)
+}
+
+func (api *API) GetProjectByNameContext(ctx context.Context, siteId, name string, parentProjectId ...string) (Project, error) {
+	opts := []QueryOption{}
+	// a name holding a comma can't be filtered on, it's matched in the full list below
+	if filterable(name) {
+		opts = append(opts, Filter().Eq("name", name))
+	}
+	projects, err := api.QueryProjectsContext(ctx, siteId, opts...)
 	if err != nil {
 		return Project{}, err
 	}
+	matches := []Project{}
 	for _, project := range projects {
-		if project.Name == name {
+		if project.Name != name {
+			continue
+		}
+		if len(parentProjectId) > 0 && project.ParentProjectID != parentProjectId[0] {
+			continue
+		}
+		if len(parentProjectId) == 0 && project.ParentProjectID == "" {
 			return project, nil
 		}
+		matches = append(matches, project)
+	}
+	switch len(matches) {
+	case 0:
+		return Project{}, fmt.Errorf("Project Named '%s' %w", name, ErrNotFound)
+	case 1:
+		return matches[0], nil
 	}
-	return Project{}, fmt.Errorf("Project Named '%s' Not Found", name)
+	return Project{}, fmt.Errorf("Project Named '%s': %w", name, ErrAmbiguousProject)
 }
 
 func (api *API) GetProjectByID(siteId, id string) (Project, error) {
-	projects, err := api.QueryProjects(siteId)
+	return api.GetProjectByIDContext(context.Background(), siteId, id)
+}
+
+func (api *API) GetProjectByIDContext(ctx context.Context, siteId, id string) (Project, error) {
+	projects, err := api.QueryProjectsContext(ctx, siteId)
 	if err != nil {
 		return Project{}, err
 	}
@@ -192,44 +330,98 @@ func (api *API) GetProjectByID(siteId, id string) (Project, error) {
 			return project, nil
 		}
 	}
-	return Project{}, fmt.Errorf("Project with ID '%s' Not Found", id)
+	return Project{}, fmt.Errorf("Project with ID '%s' %w", id, ErrNotFound)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Datasources%3FTocPath%3DAPI%2520Reference%7C_____33
-func (api *API) QueryDatasources(siteId string, datasourceName string) ([]Datasource, error) {
+func (api *API) QueryDatasources(siteId string, datasourceName string, opts ...QueryOption) ([]Datasource, error) {
+	return api.QueryDatasourcesContext(context.Background(), siteId, datasourceName, opts...)
+}
+
+func (api *API) QueryDatasourcesContext(ctx context.Context, siteId string, datasourceName string, opts ...QueryOption) ([]Datasource, error) {
 	// jbarefoot: We don't do any paging here, but setting the pageSize to the max of 1000 + filter by name should work
-	var requestUrl string
+	opts = append([]QueryOption{WithPageSize(1000)}, opts...)
 	if datasourceName != "" {
-		requestUrl = fmt.Sprintf("%s/api/%s/sites/%s/datasources?pageSize=1000&filter=name:eq:%s", api.Server, api.Version, siteId, url.QueryEscape(datasourceName))
-	} else {
-		requestUrl = fmt.Sprintf("%s/api/%s/sites/%s/datasources?pageSize=1000", api.Server, api.Version, siteId)
+		opts = append(opts, Filter().Eq("name", datasourceName))
 	}
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/datasources", api.Server, api.Version, siteId), 1, opts)
 
 	headers := make(map[string]string)
 	retval := QueryDatasourcesResponse{}
-	err := api.makeRequest(requestUrl, GET, nil, &retval, headers)
-	if api.Debug {
-		fmt.Printf("Found %d datasources for siteId %s \n", len(retval.Datasources.Datasources), siteId)
-	}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	api.loggerFor(ctx).Debugf("Found %d datasources for siteId %s", len(retval.Datasources.Datasources), siteId)
 	return retval.Datasources.Datasources, err
 }
 
+// pages through every datasource on the site matching opts, where QueryDatasources stops after 1000
+func (api *API) QueryAllDatasources(siteId string, opts ...QueryOption) ([]Datasource, error) {
+	return api.QueryAllDatasourcesContext(context.Background(), siteId, opts...)
+}
+
+func (api *API) QueryAllDatasourcesContext(ctx context.Context, siteId string, opts ...QueryOption) ([]Datasource, error) {
+	opts = append([]QueryOption{WithPageSize(1000)}, opts...)
+	totalAvailable := 1
+	datasources := []Datasource{}
+	for i := 1; len(datasources) < totalAvailable; i++ {
+		datasourcesResponse, err := api.QueryDatasourcesByPageContext(ctx, siteId, i, opts...)
+		if err != nil {
+			return datasources, err
+		}
+		datasources = append(datasources, datasourcesResponse.Datasources.Datasources...)
+		totalAvailable = datasourcesResponse.Pagination.TotalAvailable
+	}
+	return datasources, nil
+}
+
+func (api *API) QueryDatasourcesByPage(siteId string, pageNum int, opts ...QueryOption) (QueryDatasourcesResponse, error) {
+	return api.QueryDatasourcesByPageContext(context.Background(), siteId, pageNum, opts...)
+}
+
+func (api *API) QueryDatasourcesByPageContext(ctx context.Context, siteId string, pageNum int, opts ...QueryOption) (QueryDatasourcesResponse, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/datasources", api.Server, api.Version, siteId), pageNum, opts)
+	headers := make(map[string]string)
+	response := QueryDatasourcesResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response, err
+}
+
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Download_Datasource%3FTocPath%3DAPI%2520Reference%7C_____34
-// NOTE: that even though this is under the /datasources path, the docs list it under "Download Datasource" and not e.g. "Query Datasource Content".
-func (api *API) getDatasourceContent(siteId, datasourceId string) (string, error) {
-	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources/%s/content?includeExtract=false", api.Server, api.Version, siteId, datasourceId)
+// returns the raw .tdsx (or .tds when the datasource has no extract) bytes
+func (api *API) DownloadDatasource(siteId, datasourceId string, includeExtract bool) ([]byte, error) {
+	return api.DownloadDatasourceContext(context.Background(), siteId, datasourceId, includeExtract)
+}
+
+func (api *API) DownloadDatasourceContext(ctx context.Context, siteId, datasourceId string, includeExtract bool) ([]byte, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources/%s/content?includeExtract=%v", api.Server, api.Version, siteId, datasourceId, includeExtract)
+	headers := make(map[string]string)
+	return api.makeRequestGetBody(withOperation(ctx, operationDownload), requestUrl, GET, nil, nil, headers)
+}
+
+// http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_View_Data
+// returns the summary data of the view as CSV
+func (api *API) DownloadViewData(siteId, viewId string) ([]byte, error) {
+	return api.DownloadViewDataContext(context.Background(), siteId, viewId)
+}
+
+func (api *API) DownloadViewDataContext(ctx context.Context, siteId, viewId string) ([]byte, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/views/%s/data", api.Server, api.Version, siteId, viewId)
 	headers := make(map[string]string)
+	return api.makeRequestGetBody(withOperation(ctx, operationDownload), requestUrl, GET, nil, nil, headers)
+}
 
-	body, err := api.makeRequestGetBody(requestUrl, GET, nil, nil, headers)
+// NOTE: that even though this is under the /datasources path, the docs list it under "Download Datasource" and not e.g. "Query Datasource Content".
+func (api *API) getDatasourceContent(ctx context.Context, siteId, datasourceId string) (string, error) {
+	body, err := api.DownloadDatasourceContext(ctx, siteId, datasourceId, false)
 	if err != nil {
 		return "", err
 	}
 
-	extractedXml, err := extractXmlFromZip(bytes.NewReader(body), int64(len(body)))
+	extractedXml, err := extractXmlFromZip(bytes.NewReader(body), int64(len(body)), api.zipLimits())
+	if errors.Is(err, ErrZipLimitExceeded) || errors.Is(err, ErrZipUnsafePath) {
+		return "", err
+	}
 	if err != nil {
-		if api.Debug {
-			fmt.Printf("For datasource with id %s: Got an error treating datasource like a zip (.tdsx), assuming it's plain xml (.tds) instead. \n", datasourceId)
-		}
+		api.loggerFor(ctx).Debugf("For datasource with id %s: Got an error treating datasource like a zip (.tdsx), assuming it's plain xml (.tds) instead.", datasourceId)
 		extractedXml = string(body)
 	}
 
@@ -238,12 +430,14 @@ func (api *API) getDatasourceContent(siteId, datasourceId string) (string, error
 
 // assumption is that the intersection of site, project, and datasource name is unique
 func (api *API) GetDatasourceContentXML(siteId, tableauProjectId, datasourceName string) (string, error) {
-	if api.Debug {
-		fmt.Printf("\n Getting data source raw xml for siteId %s, tableauProjectId %s, and datasourceName %s \n", siteId, tableauProjectId, datasourceName)
-	}
+	return api.GetDatasourceContentXMLContext(context.Background(), siteId, tableauProjectId, datasourceName)
+}
+
+func (api *API) GetDatasourceContentXMLContext(ctx context.Context, siteId, tableauProjectId, datasourceName string) (string, error) {
+	api.loggerFor(ctx).Debugf("Getting data source raw xml for siteId %s, tableauProjectId %s, and datasourceName %s", siteId, tableauProjectId, datasourceName)
 
 	var datasource *Datasource
-	datasources, err := api.QueryDatasources(siteId, datasourceName)
+	datasources, err := api.QueryDatasourcesContext(ctx, siteId, datasourceName)
 	if err != nil {
 		return "", err
 	}
@@ -257,33 +451,33 @@ func (api *API) GetDatasourceContentXML(siteId, tableauProjectId, datasourceName
 	}
 
 	if datasource == nil {
-		if api.Debug {
-			fmt.Printf("Could not find datasource for siteId %s, tableauProjectId %s, and datasourceName %s \n", siteId, tableauProjectId, datasourceName)
-		}
+		api.loggerFor(ctx).Debugf("Could not find datasource for siteId %s, tableauProjectId %s, and datasourceName %s", siteId, tableauProjectId, datasourceName)
 		return "", nil
 	}
 
-	datasourceXML, err := api.getDatasourceContent(siteId, datasource.ID)
+	datasourceXML, err := api.getDatasourceContent(ctx, siteId, datasource.ID)
 
 	if err != nil {
 		return "", err
 	}
 
-	if api.Debug {
-		fmt.Printf("Got raw xml for datasource with id %s, raw xml is: \n %s \n", datasource.ID, datasourceXML)
-	}
+	api.loggerFor(ctx).Debugf("Got raw xml for datasource with id %s, raw xml is: \n %s", datasource.ID, datasourceXML)
 
 	return datasourceXML, nil
 }
 
 // A .tdsx is really just a zip file containing the .tds XML
-func extractXmlFromZip(in io.ReaderAt, size int64) (string, error) {
+func extractXmlFromZip(in io.ReaderAt, size int64, limits ZipLimits) (string, error) {
 	r, err := zip.NewReader(in, size)
 
 	if err != nil {
 		return "", err
 	}
 
+	if err = limits.check(r); err != nil {
+		return "", err
+	}
+
 	var datasourceFile *zip.File
 	if len(r.File) != 1 {
 		return "", errors.New("A .tdsx file is expect to be a zip file containing exactly one file, the .tds datasource")
@@ -300,7 +494,7 @@ func extractXmlFromZip(in io.ReaderAt, size int64) (string, error) {
 	defer readerCloser.Close()
 
 	buf := new(bytes.Buffer)
-	if _, err = buf.ReadFrom(readerCloser); err != nil {
+	if err = limits.copy(buf, readerCloser); err != nil {
 		return "", err
 	}
 
@@ -308,7 +502,11 @@ func extractXmlFromZip(in io.ReaderAt, size int64) (string, error) {
 }
 
 func (api *API) GetSiteID(siteName string) (string, error) {
-	site, err := api.QuerySiteByName(siteName, false)
+	return api.GetSiteIDContext(context.Background(), siteName)
+}
+
+func (api *API) GetSiteIDContext(ctx context.Context, siteName string) (string, error) {
+	site, err := api.QuerySiteByNameContext(ctx, siteName, false)
 	if err != nil {
 		return "", err
 	}
@@ -317,8 +515,12 @@ func (api *API) GetSiteID(siteName string) (string, error) {
 
 // use this method to easily get the site by name
 func (api *API) GetSite(siteName string) (Site, error) {
+	return api.GetSiteContext(context.Background(), siteName)
+}
+
+func (api *API) GetSiteContext(ctx context.Context, siteName string) (Site, error) {
 	if siteName == api.DefaultSiteName {
-		site, err := api.QuerySiteByName(siteName, false)
+		site, err := api.QuerySiteByNameContext(ctx, siteName, false)
 		if err != nil {
 			return site, err
 		}
@@ -326,7 +528,7 @@ func (api *API) GetSite(siteName string) (Site, error) {
 	}
 
 	contentUrl := ConvertSiteNameToContentUrl(siteName)
-	site, err := api.QuerySiteByContentUrl(contentUrl, false)
+	site, err := api.QuerySiteByContentUrlContext(ctx, contentUrl, false)
 	if err != nil {
 		return site, err
 	}
@@ -337,167 +539,473 @@ func (api *API) GetSite(siteName string) (Site, error) {
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Create_Project%3FTocPath%3DAPI%2520Reference%7C_____14
 // POST /api/api-version/sites/site-id/projects
 func (api *API) CreateProject(siteId string, project Project) (*Project, error) {
+	return api.CreateProjectContext(context.Background(), siteId, project)
+}
+
+func (api *API) CreateProjectContext(ctx context.Context, siteId string, project Project) (*Project, error) {
 	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/projects", api.Server, api.Version, siteId)
-	createProjectRequest := CreateProjectRequest{Request: project}
-	xmlRep, err := createProjectRequest.XML()
+	payload, headers, err := api.encodeRequest(CreateProjectRequest{Request: project})
 	if err != nil {
 		return nil, err
 	}
-	headers := make(map[string]string)
-	headers[contentTypeHeader] = applicationXmlContentType
 	createProjectResponse := CreateProjectResponse{}
-	err = api.makeRequest(requestUrl, POST, xmlRep, &createProjectResponse, headers)
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &createProjectResponse, headers)
 	return &createProjectResponse.Project, err
 }
 
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_projects.htm#update_project
+// e.g. UpdateProject(siteId, projectId, ProjectUpdate{Name: String("Finance"), Description: String("Quarterly close")})
+func (api *API) UpdateProject(siteId, projectId string, update ProjectUpdate) (Project, error) {
+	return api.UpdateProjectContext(context.Background(), siteId, projectId, update)
+}
+
+func (api *API) UpdateProjectContext(ctx context.Context, siteId, projectId string, update ProjectUpdate) (Project, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/projects/%s", api.Server, api.Version, siteId, projectId)
+	payload, headers, err := api.encodeRequest(UpdateProjectRequest{Request: update})
+	if err != nil {
+		return Project{}, err
+	}
+	retval := CreateProjectResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.Project, err
+}
+
+// returns the project named project.Name under project.ParentProjectID, creating it when there is none.
+// Safe to call concurrently, when another caller creates the project first it is looked up again.
+func (api *API) EnsureProject(siteId string, project Project) (Project, error) {
+	return api.EnsureProjectContext(context.Background(), siteId, project)
+}
+
+func (api *API) EnsureProjectContext(ctx context.Context, siteId string, project Project) (Project, error) {
+	existing, err := api.GetProjectByNameContext(ctx, siteId, project.Name, project.ParentProjectID)
+	if !errors.Is(err, ErrNotFound) {
+		return existing, err
+	}
+	created, err := api.CreateProjectContext(ctx, siteId, project)
+	if errors.Is(err, ErrConflict) {
+		api.loggerFor(ctx).Debugf("Project %s was created concurrently on siteId %s, looking it up again", project.Name, siteId)
+		return api.GetProjectByNameContext(ctx, siteId, project.Name, project.ParentProjectID)
+	}
+	if err != nil {
+		return Project{}, err
+	}
+	return *created, nil
+}
+
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Publish_Datasource%3FTocPath%3DAPI%2520Reference%7C_____31
 func (api *API) PublishTDS(siteId string, tdsMetadata Datasource, fullTds string, overwrite bool) (*Datasource, error) {
-	return api.publishDatasource(siteId, tdsMetadata, fullTds, "tds", overwrite)
+	return api.PublishTDSContext(context.Background(), siteId, tdsMetadata, fullTds, overwrite)
+}
+
+func (api *API) PublishTDSContext(ctx context.Context, siteId string, tdsMetadata Datasource, fullTds string, overwrite bool) (*Datasource, error) {
+	return api.publishDatasource(ctx, siteId, tdsMetadata, fullTds, "tds", DatasourcePublishOptions{Overwrite: overwrite})
+}
+
+// http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Publish_Datasource%3FTocPath%3DAPI%2520Reference%7C_____31
+func (api *API) PublishTDSWithOptions(siteId string, tdsMetadata Datasource, fullTds string, options DatasourcePublishOptions) (*Datasource, error) {
+	return api.PublishTDSWithOptionsContext(context.Background(), siteId, tdsMetadata, fullTds, options)
+}
+
+func (api *API) PublishTDSWithOptionsContext(ctx context.Context, siteId string, tdsMetadata Datasource, fullTds string, options DatasourcePublishOptions) (*Datasource, error) {
+	return api.publishDatasource(ctx, siteId, tdsMetadata, fullTds, "tds", options)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Publish_Datasource%3FTocPath%3DAPI%2520Reference%7C_____31
-func (api *API) publishDatasource(siteId string, tdsMetadata Datasource, datasource string, datasourceType string, overwrite bool) (*Datasource, error) {
-	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources?datasourceType=%s&overwrite=%v", api.Server, api.Version, siteId, datasourceType, overwrite)
-	payload := fmt.Sprintf("--%s\r\n", api.Boundary)
-	payload += "Content-Disposition: name=\"request_payload\"\r\n"
-	payload += "Content-Type: text/xml\r\n"
-	payload += "\r\n"
-	tdsRequest := DatasourceCreateRequest{Request: tdsMetadata}
-	xmlRepresentation, err := tdsRequest.XML()
+func (api *API) publishDatasource(ctx context.Context, siteId string, tdsMetadata Datasource, datasource string, datasourceType string, options DatasourcePublishOptions) (*Datasource, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources?datasourceType=%s&overwrite=%v", api.Server, api.Version, siteId, datasourceType, options.Overwrite)
+	if options.UseRemoteQueryAgent {
+		requestUrl += "&useRemoteQueryAgent=true"
+	}
+	requestPayload, err := api.codec().Marshal(DatasourceCreateRequest{Request: tdsMetadata})
 	if err != nil {
 		return nil, err
 	}
+	prefix, suffix := api.multipartParts(requestPayload, "tableau_datasource", tdsMetadata.Name+".tds")
+	payload := append(append(prefix, datasource...), suffix...)
 
-	payload += string(xmlRepresentation)
-	payload += fmt.Sprintf("\r\n--%s\r\n", api.Boundary)
-	payload += fmt.Sprintf("Content-Disposition: name=\"tableau_datasource\"; filename=\"%s.tds\"\r\n", tdsMetadata.Name)
-	payload += "Content-Type: application/octet-stream\r\n"
-	payload += "\r\n"
-	payload += datasource
-	payload += fmt.Sprintf("\r\n--%s--\r\n", api.Boundary)
-	headers := make(map[string]string)
-	headers[contentTypeHeader] = fmt.Sprintf("multipart/mixed; boundary=%s", api.Boundary)
+	retval := PublishDatasourceResponse{}
+	err = api.makeRequest(withOperation(ctx, operationPublish), requestUrl, POST, payload, &retval, api.multipartHeaders())
+	return &retval.Datasource, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_source_now
+// starts an extract refresh and returns the job tracking it. Set useRemoteQueryAgent to have a Tableau Bridge
+// client run the refresh.
+func (api *API) RefreshDatasource(siteId string, datasourceId string, useRemoteQueryAgent bool) (Job, error) {
+	return api.RefreshDatasourceContext(context.Background(), siteId, datasourceId, useRemoteQueryAgent)
+}
 
-	var retDatasource *Datasource
-	err = api.makeRequest(requestUrl, POST, []byte(payload), retDatasource, headers)
-	return retDatasource, err
+func (api *API) RefreshDatasourceContext(ctx context.Context, siteId string, datasourceId string, useRemoteQueryAgent bool) (Job, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources/%s/refresh", api.Server, api.Version, siteId, datasourceId)
+	if useRemoteQueryAgent {
+		requestUrl += "?useRemoteQueryAgent=true"
+	}
+	payload, headers, err := api.encodeRequest(struct{}{})
+	if err != nil {
+		return Job{}, err
+	}
+	retval := QueryJobResponse{}
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	return retval.Job, err
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Datasource%3FTocPath%3DAPI%2520Reference%7C_____15
 func (api *API) DeleteDatasource(siteId string, datasourceId string) error {
+	return api.DeleteDatasourceContext(context.Background(), siteId, datasourceId)
+}
+
+func (api *API) DeleteDatasourceContext(ctx context.Context, siteId string, datasourceId string) error {
 	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources/%s", api.Server, api.Version, siteId, datasourceId)
-	return api.delete(requestUrl)
+	return api.delete(ctx, requestUrl)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Project%3FTocPath%3DAPI%2520Reference%7C_____17
 func (api *API) DeleteProject(siteId string, projectId string) error {
+	return api.DeleteProjectContext(context.Background(), siteId, projectId)
+}
+
+func (api *API) DeleteProjectContext(ctx context.Context, siteId string, projectId string) error {
 	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/projects/%s", api.Server, api.Version, siteId, projectId)
-	return api.delete(requestUrl)
+	return api.delete(ctx, requestUrl)
+}
+
+// ErrProjectNotEmpty is returned by DeleteProjectSafe for projects holding content, which deleting them would delete
+var ErrProjectNotEmpty = errors.New("project isn't empty")
+
+// the content a project holds directly, the content of nested projects is in those
+type ProjectContents struct {
+	Workbooks   []Workbook
+	Datasources []Datasource
+	Projects    []Project
+}
+
+func (c ProjectContents) Empty() bool {
+	return len(c.Workbooks) == 0 && len(c.Datasources) == 0 && len(c.Projects) == 0
+}
+
+func (api *API) GetProjectContents(siteId, projectId string) (ProjectContents, error) {
+	return api.GetProjectContentsContext(context.Background(), siteId, projectId)
+}
+
+func (api *API) GetProjectContentsContext(ctx context.Context, siteId, projectId string) (ProjectContents, error) {
+	contents := ProjectContents{Workbooks: []Workbook{}, Datasources: []Datasource{}, Projects: []Project{}}
+	project, err := api.GetProjectByIDContext(ctx, siteId, projectId)
+	if err != nil {
+		return contents, err
+	}
+	// the filters narrow the listings down, names aren't unique across nested projects so the ids are compared
+	workbooks, err := api.QueryWorkbooksContext(ctx, siteId, Filter().Eq("projectName", project.Name))
+	if err != nil {
+		return contents, err
+	}
+	for _, workbook := range workbooks {
+		if workbook.Project != nil && workbook.Project.ID == projectId {
+			contents.Workbooks = append(contents.Workbooks, workbook)
+		}
+	}
+	datasources, err := api.QueryDatasourcesContext(ctx, siteId, "", Filter().Eq("projectName", project.Name))
+	if err != nil {
+		return contents, err
+	}
+	for _, datasource := range datasources {
+		if datasource.Project != nil && datasource.Project.ID == projectId {
+			contents.Datasources = append(contents.Datasources, datasource)
+		}
+	}
+	projects, err := api.QueryProjectsContext(ctx, siteId, Filter().Eq("parentProjectId", projectId))
+	if err != nil {
+		return contents, err
+	}
+	for _, child := range projects {
+		if child.ParentProjectID == projectId {
+			contents.Projects = append(contents.Projects, child)
+		}
+	}
+	return contents, nil
+}
+
+// deletes the project only when it holds no workbooks, datasources or nested projects, failing with
+// ErrProjectNotEmpty otherwise. force deletes it regardless, together with all of its content.
+func (api *API) DeleteProjectSafe(siteId, projectId string, force bool) error {
+	return api.DeleteProjectSafeContext(context.Background(), siteId, projectId, force)
+}
+
+func (api *API) DeleteProjectSafeContext(ctx context.Context, siteId, projectId string, force bool) error {
+	contents, err := api.GetProjectContentsContext(ctx, siteId, projectId)
+	if err != nil {
+		return err
+	}
+	if !contents.Empty() {
+		if !force {
+			return fmt.Errorf("%w: projectId %s holds %d workbooks, %d datasources and %d nested projects", ErrProjectNotEmpty,
+				projectId, len(contents.Workbooks), len(contents.Datasources), len(contents.Projects))
+		}
+		api.loggerFor(ctx).Infof("Deleting projectId %s on siteId %s with %d workbooks, %d datasources and %d nested projects", projectId, siteId,
+			len(contents.Workbooks), len(contents.Datasources), len(contents.Projects))
+	}
+	return api.DeleteProjectContext(ctx, siteId, projectId)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Project%3FTocPath%3DAPI%2520Reference%7C_____17
 func (api *API) DeleteSite(siteId string) error {
-	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, siteId)
-	return api.delete(requestUrl)
+	return api.DeleteSiteContext(context.Background(), siteId)
+}
+
+func (api *API) DeleteSiteContext(ctx context.Context, siteId string) error {
+	return api.DeleteSiteByKeyContext(ctx, SiteKeyID, siteId)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
 func (api *API) DeleteSiteByName(name string) error {
-	return api.deleteSiteByKey("name", name)
+	return api.DeleteSiteByNameContext(context.Background(), name)
+}
+
+func (api *API) DeleteSiteByNameContext(ctx context.Context, name string) error {
+	return api.DeleteSiteByKeyContext(ctx, SiteKeyName, name)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
 func (api *API) DeleteSiteByContentUrl(contentUrl string) error {
-	return api.deleteSiteByKey("contentUrl", contentUrl)
+	return api.DeleteSiteByContentUrlContext(context.Background(), contentUrl)
+}
+
+func (api *API) DeleteSiteByContentUrlContext(ctx context.Context, contentUrl string) error {
+	return api.DeleteSiteByKeyContext(ctx, SiteKeyContentURL, contentUrl)
 }
 
 // http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
-func (api *API) deleteSiteByKey(key string, value string) error {
-	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s?key=%s", api.Server, api.Version, value, key)
-	return api.delete(requestUrl)
+func (api *API) DeleteSiteByKey(key SiteKey, value string) error {
+	return api.DeleteSiteByKeyContext(context.Background(), key, value)
 }
 
-func (api *API) delete(requestUrl string) error {
+func (api *API) DeleteSiteByKeyContext(ctx context.Context, key SiteKey, value string) error {
+	requestUrl, err := api.siteUrl(key, value)
+	if err != nil {
+		return err
+	}
+	return api.delete(ctx, requestUrl)
+}
+
+// SiteKey names what identifies a site in LookupSite and DeleteSiteByKey
+type SiteKey string
+
+const SiteKeyID SiteKey = "id"
+const SiteKeyName SiteKey = "name"
+const SiteKeyContentURL SiteKey = "contentUrl"
+
+// the url of a site, the value is escaped as names may hold spaces, slashes or ampersands
+func (api *API) siteUrl(key SiteKey, value string) (string, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, url.PathEscape(value))
+	switch key {
+	case SiteKeyID:
+		return requestUrl, nil
+	case SiteKeyName, SiteKeyContentURL:
+		return requestUrl + "?key=" + url.QueryEscape(string(key)), nil
+	}
+	return "", fmt.Errorf("unknown site key '%s'", key)
+}
+
+func querySeparator(requestUrl string) string {
+	if strings.Contains(requestUrl, "?") {
+		return "&"
+	}
+	return "?"
+}
+
+func (api *API) delete(ctx context.Context, requestUrl string) error {
 	headers := make(map[string]string)
-	return api.makeRequest(requestUrl, DELETE, nil, nil, headers)
+	return api.makeRequest(ctx, requestUrl, DELETE, nil, nil, headers)
 }
 
-func (api *API) makeRequest(requestUrl string, method string, payload []byte, result interface{}, headers map[string]string) error {
-	_, err := api.makeRequestGetBody(requestUrl, method, payload, result, headers)
+// every exported call has a ...Context variant, ctx is attached to each http request so it also cancels
+// the re-authentication or version fallback a call triggers
+func (api *API) makeRequest(ctx context.Context, requestUrl string, method string, payload []byte, result interface{}, headers map[string]string) error {
+	_, err := api.makeRequestGetBody(ctx, requestUrl, method, payload, result, headers)
 	return err
 }
 
-//nolint:gocognit // TODO: refactor to smaller functions
-func (api *API) makeRequestGetBody(requestUrl string, method string, payload []byte, result interface{}, headers map[string]string) ([]byte, error) {
-	if api.Debug {
-		fmt.Printf("%s:%v\n", method, requestUrl)
-		if payload != nil {
-			fmt.Printf("%v\n", string(payload))
+func (api *API) makeRequestGetBody(ctx context.Context, requestUrl string, method string, payload []byte, result interface{}, headers map[string]string) ([]byte, error) {
+	return api.sendRequest(ctx, requestUrl, method, payload, nil, result, headers)
+}
+
+// upload streams the body instead of payload, requests with an upload that can't be replayed are sent only once
+func (api *API) sendRequest(ctx context.Context, requestUrl string, method string, payload []byte, upload *uploadBody, result interface{}, headers map[string]string) ([]byte, error) {
+	if err := checkVersion(requestUrl); err != nil {
+		return nil, err
+	}
+	ctx, requestID := api.withRequestID(ctx)
+	ctx, cancel := api.withTimeout(ctx)
+	defer cancel()
+	body, err := api.sendWithRecovery(ctx, requestUrl, method, payload, upload, result, headers)
+	if err != nil && requestID != "" {
+		return body, &RequestError{RequestID: requestID, Err: err}
+	}
+	return body, err
+}
+
+// re-authenticates and falls back to the server's API version as needed
+func (api *API) sendWithRecovery(ctx context.Context, requestUrl string, method string, payload []byte, upload *uploadBody, result interface{}, headers map[string]string) ([]byte, error) {
+	staleToken := api.Token()
+	body, err := api.doRequestWithRetry(ctx, requestUrl, method, payload, upload, result, headers)
+	if isTokenExpired(err) && !isSigninUrl(requestUrl) && upload.replayable() {
+		if reauthErr := api.reauthenticate(ctx, staleToken); reauthErr != nil {
+			api.loggerFor(ctx).Errorf("t4g re-authentication failed:%v", reauthErr)
+			return body, err
 		}
+		body, err = api.doRequestWithRetry(ctx, requestUrl, method, payload, upload, result, headers)
 	}
+	if api.VersionFallback && isEndpointMissing(err) && upload.replayable() {
+		return api.retryWithServerVersion(ctx, requestUrl, method, payload, upload, result, headers, err)
+	}
+	return body, err
+}
 
-	client := NewTimeoutClient(api.ConnectTimeout, api.ReadTimeout, true)
+//nolint:gocognit // TODO: refactor to smaller functions
+func (api *API) doRequest(ctx context.Context, requestUrl string, method string, payload []byte, upload *uploadBody, result interface{}, headers map[string]string) ([]byte, error) {
+	api.loggerFor(ctx).Debugf("%s:%v", method, requestUrl)
+	if payload != nil {
+		api.loggerFor(ctx).Debugf("%v", redactedBody(payload))
+	}
+
+	cacheKey, cached := api.cachedResponse(ctx, method, requestUrl, result)
+	if method != GET {
+		// whether or not it succeeds, a POST, PUT or DELETE may have changed what cached listings hold
+		defer api.invalidateCache()
+	}
+	if cached != nil && cached.fresh(api.CacheTTL) {
+		api.loggerFor(ctx).Debugf("t4g cached response")
+		captureResponse(ctx, method, requestUrl, nil, cached.Body)
+		return cached.Body, api.unmarshalResult(ctx, requestUrl, cached.Body, result)
+	}
+
+	client, err := api.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	contentEncoding := ""
+	if api.GzipRequests && upload == nil && len(payload) >= gzipMinRequestSize {
+		if payload, err = gzipPayload(payload); err != nil {
+			return nil, err
+		}
+		contentEncoding = gzipEncoding
+	}
 	var req *http.Request
-	if len(payload) > 0 {
+	if upload != nil {
+		reader, size, uploadErr := upload.reader()
+		if uploadErr != nil {
+			return nil, uploadErr
+		}
+		api.loggerFor(ctx).Debugf("streaming %d bytes", size)
+		if req, err = http.NewRequestWithContext(ctx, strings.TrimSpace(method), strings.TrimSpace(requestUrl), reader); err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+	} else if len(payload) > 0 {
 		var httpErr error
-		req, httpErr = http.NewRequest(strings.TrimSpace(method), strings.TrimSpace(requestUrl), bytes.NewBuffer(payload))
+		req, httpErr = http.NewRequestWithContext(ctx, strings.TrimSpace(method), strings.TrimSpace(requestUrl), bytes.NewBuffer(payload))
 		if httpErr != nil {
 			return nil, httpErr
 		}
 		req.Header.Add(contentLengthHeader, strconv.Itoa(len(payload)))
 	} else {
 		var httpErr error
-		req, httpErr = http.NewRequest(strings.TrimSpace(method), strings.TrimSpace(requestUrl), nil)
+		req, httpErr = http.NewRequestWithContext(ctx, strings.TrimSpace(method), strings.TrimSpace(requestUrl), nil)
 		if httpErr != nil {
 			return nil, httpErr
 		}
 	}
 
+	if contentEncoding != "" {
+		req.Header.Set(contentEncodingHeader, contentEncoding)
+	}
+	// asking for gzip ourselves rather than leaving it to the transport makes it work with any Transport
+	req.Header.Set(acceptEncodingHeader, gzipEncoding)
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set(api.requestIDHeader(), requestID)
+	}
+	userAgent := api.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set(userAgentHeader, userAgent)
+	// the server answers in the format it's asked for, file downloads are sent as they are
+	if _, streaming := result.(*streamTo); result != nil && !streaming {
+		req.Header.Set(acceptHeader, api.codec().ContentType())
+	}
+	for header, headerValue := range api.DefaultHeaders {
+		req.Header.Set(header, headerValue)
+	}
 	for header, headerValue := range headers {
-		req.Header.Add(header, headerValue)
+		req.Header.Set(header, headerValue)
 	}
 
-	if len(api.AuthToken) > 0 {
-		if api.Debug {
-			fmt.Printf("%s:%s\n", authHeader, api.AuthToken)
-		}
-		req.Header.Add(authHeader, api.AuthToken)
+	if token := api.Token(); len(token) > 0 {
+		api.loggerFor(ctx).Debugf("%s:%s", authHeader, redacted)
+		req.Header.Add(authHeader, token)
+	}
+	if cached != nil {
+		cached.setConditionalHeaders(req)
 	}
 
-	var httpErr error
+	start := time.Now()
 	resp, httpErr := client.Do(req)
 	if httpErr != nil {
+		api.observeRequest(requestUrl, method, 0, time.Since(start), httpErr)
 		return nil, httpErr
 	}
 	defer resp.Body.Close()
+	if err = decompressResponse(resp); err != nil {
+		api.observeRequest(requestUrl, method, resp.StatusCode, time.Since(start), err)
+		return nil, err
+	}
+	if api.stats != nil {
+		api.stats.record(resp, time.Now())
+	}
+	if stream, ok := result.(*streamTo); ok && resp.StatusCode < http.StatusMultipleChoices {
+		captureResponse(ctx, method, requestUrl, resp, nil)
+		return nil, api.stream(stream, resp, requestUrl, method, start)
+	}
 	body, readBodyError := ioutil.ReadAll(resp.Body)
-
-	if api.Debug {
-		fmt.Printf("t4g Response:%v\n", body)
+	api.observeRequest(requestUrl, method, resp.StatusCode, time.Since(start), readBodyError)
+	if resp.StatusCode == http.StatusNotModified && cached != nil && readBodyError == nil {
+		api.loggerFor(ctx).Debugf("t4g cached response not modified")
+		body = cached.Body
+		resp.StatusCode = http.StatusOK
+		// a 304 may leave out the validators
+		if resp.Header.Get(etagHeader) == "" && resp.Header.Get(lastModifiedHeader) == "" {
+			resp.Header.Set(etagHeader, cached.ETag)
+			resp.Header.Set(lastModifiedHeader, cached.LastModified)
+		}
 	}
 
+	api.loggerFor(ctx).Debugf("t4g Response:%v", redactedBody(body))
+
 	if readBodyError != nil {
 		return nil, readBodyError
 	}
+	captureResponse(ctx, method, requestUrl, resp, body)
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, &StatusError{Code: http.StatusNotFound, Msg: "Resource not found", URL: requestUrl}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return body, api.responseError(strings.TrimSpace(method), requestUrl, resp, body)
+	}
+	api.touchSession()
+	if cacheKey != "" {
+		api.cacheResponse(cacheKey, resp, body)
 	}
+	return body, api.unmarshalResult(ctx, requestUrl, body, result)
+}
 
-	if resp.StatusCode >= http.StatusMultipleChoices {
-		tErrorResponse := ErrorResponse{}
-		err := xml.Unmarshal(body, &tErrorResponse)
-		if err != nil {
-			return body, err
-		}
-		return body, tErrorResponse.Error
+func (api *API) unmarshalResult(ctx context.Context, requestUrl string, body []byte, result interface{}) error {
+	if result == nil {
+		return nil
 	}
-	if result != nil {
-		// else unmarshall to the result type specified by caller
-		err := xml.Unmarshal(body, &result)
-		if err != nil {
-			return body, err
-		}
+	if err := api.checkStrict(requestUrl, body, result); err != nil {
+		return err
+	}
+	// else unmarshall to the result type specified by caller
+	if err := api.codec().Unmarshal(body, result); err != nil {
+		return err
 	}
-	return body, nil
+	api.validateResponse(ctx, requestUrl, body, result)
+	return nil
 }
@@ -0,0 +1,76 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// a server listing the given xml elements under the listing element, it fails requests with a filter
+// value holding a comma as Tableau does and filters by name otherwise
+func listingServer(t *testing.T, listing string, elements map[string]string) API {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		filter := r.URL.Query().Get("filter")
+		for _, expression := range strings.Split(filter, ",") {
+			if expression != "" && strings.Count(expression, ":") < 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `<tsResponse><error code="400065"><summary>Bad Request</summary><detail>malformed filter %s</detail></error></tsResponse>`, filter)
+				return
+			}
+		}
+		matched := []string{}
+		for name, element := range elements {
+			if name := "name:eq:" + name; filter == "" || filter == name {
+				matched = append(matched, element)
+			}
+		}
+		fmt.Fprintf(w, `<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="%d"/><%s>%s</%s></tsResponse>`,
+			len(matched), listing, strings.Join(matched, ""), listing)
+	}))
+	t.Cleanup(server.Close)
+	return NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+}
+
+func TestGetProjectByName(t *testing.T) {
+	api := listingServer(t, "projects", map[string]string{
+		"Sales":       `<project id="1" name="Sales"/>`,
+		"Sales, EMEA": `<project id="2" name="Sales, EMEA"/>`,
+		"Nested":      `<project id="3" name="Nested" parentProjectId="1"/>`,
+	})
+	tests := []struct {
+		name   string
+		parent []string
+		want   string
+	}{
+		{"Sales", nil, "1"},
+		{"Sales, EMEA", nil, "2"},
+		{"Nested", nil, "3"},
+		{"Nested", []string{"1"}, "3"},
+	}
+	for _, test := range tests {
+		project, err := api.GetProjectByName("site", test.name, test.parent...)
+		if err != nil || project.ID != test.want {
+			t.Errorf("GetProjectByName(%q, %v) = %+v, %v, want id %s", test.name, test.parent, project, err, test.want)
+		}
+	}
+	if _, err := api.GetProjectByName("site", "Missing, too"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetProjectByName of a missing project returned %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Codec turns request structs into bodies and response bodies back into structs. Set API.Codec to swap in a
+// faster implementation or to handle oddly shaped payloads without touching the request plumbing.
+type Codec interface {
+	// the Content-Type of the bodies produced by Marshal
+	ContentType() string
+	// Marshal encodes a request, for the REST API's XML flavor that means wrapping it in a tsRequest element
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// XMLCodec is the default Codec, built on encoding/xml
+type XMLCodec struct{}
+
+func (XMLCodec) ContentType() string {
+	return applicationXmlContentType
+}
+
+func (XMLCodec) Marshal(v interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	encoder := xml.NewEncoder(buf)
+	encoder.Indent("", "   ")
+	if err := encoder.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: "tsRequest"}}); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (XMLCodec) Unmarshal(data []byte, v interface{}) error {
+	return xml.Unmarshal(data, v)
+}
+
+// JSONCodec talks to the REST API in JSON, which parses faster than XML. Set API.Codec = JSONCodec{} to use it,
+// the model structs carry json tags matching their xml ones.
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return applicationJsonContentType
+}
+
+// JSON requests have no tsRequest element, the request wrappers' json tags name the top level object
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Tableau renders numbers and booleans of its JSON responses as strings, e.g. "pageSize": "100", they're
+// converted to the types of the fields being decoded into before unmarshalling
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return err
+	}
+	coerced, err := json.Marshal(coerceJSON(reflect.TypeOf(v), tree))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(coerced, v)
+}
+
+// converts the decoded JSON value to the shape t expects where the two differ in an unambiguous way
+func coerceJSON(t reflect.Type, value interface{}) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || value == nil || reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return value
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" || field.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if fieldValue, ok := object[name]; ok {
+				object[name] = coerceJSON(field.Type, fieldValue)
+			}
+		}
+		return object
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			// a list with a single item may come as the item itself
+			items = []interface{}{value}
+		}
+		for i := range items {
+			items[i] = coerceJSON(t.Elem(), items[i])
+		}
+		return items
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		for key := range object {
+			object[key] = coerceJSON(t.Elem(), object[key])
+		}
+		return object
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if text, ok := value.(string); ok {
+			if text == "" {
+				return nil
+			}
+			return json.Number(text)
+		}
+	case reflect.Bool:
+		if text, ok := value.(string); ok {
+			if parsed, err := strconv.ParseBool(text); err == nil {
+				return parsed
+			}
+		}
+	case reflect.String:
+		switch typed := value.(type) {
+		case json.Number:
+			return typed.String()
+		case bool:
+			return fmt.Sprint(typed)
+		}
+	}
+	return value
+}
+
+func (api *API) codec() Codec {
+	if api.Codec == nil {
+		return XMLCodec{}
+	}
+	return api.Codec
+}
+
+// encodes request with the API's codec and returns it along with the matching Content-Type header
+func (api *API) encodeRequest(request interface{}) ([]byte, map[string]string, error) {
+	codec := api.codec()
+	payload, err := codec.Marshal(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	headers := make(map[string]string)
+	headers[contentTypeHeader] = codec.ContentType()
+	return payload, headers, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tableau's JSON renders numbers and booleans as strings and single item lists as the item itself
+func TestJSONCodecUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want QueryWorkbooksResponse
+	}{
+		{
+			name: "numbers and booleans as strings",
+			body: `{"pagination": {"pageNumber": "1", "pageSize": "100", "totalAvailable": "2"},
+				"workbooks": {"workbook": [{"id": "a", "showTabs": "true", "size": "3"}, {"id": "b", "showTabs": "false", "size": "12"}]}}`,
+			want: QueryWorkbooksResponse{
+				Pagination: Pagination{PageNumber: 1, PageSize: 100, TotalAvailable: 2},
+				Workbooks:  Workbooks{Workbooks: []Workbook{{ID: "a", ShowTabs: true, Size: 3}, {ID: "b", Size: 12}}},
+			},
+		},
+		{
+			name: "numbers and booleans as themselves",
+			body: `{"pagination": {"pageNumber": 1, "pageSize": 100, "totalAvailable": 1}, "workbooks": {"workbook": [{"id": "a", "showTabs": true, "size": 3}]}}`,
+			want: QueryWorkbooksResponse{
+				Pagination: Pagination{PageNumber: 1, PageSize: 100, TotalAvailable: 1},
+				Workbooks:  Workbooks{Workbooks: []Workbook{{ID: "a", ShowTabs: true, Size: 3}}},
+			},
+		},
+		{
+			name: "empty number",
+			body: `{"pagination": {"pageNumber": "1", "pageSize": "", "totalAvailable": "0"}}`,
+			want: QueryWorkbooksResponse{Pagination: Pagination{PageNumber: 1}},
+		},
+		{
+			name: "single item for a list",
+			body: `{"workbooks": {"workbook": {"id": "a", "tags": {"tag": {"label": "finance"}}}}}`,
+			want: QueryWorkbooksResponse{Workbooks: Workbooks{Workbooks: []Workbook{{ID: "a", Tags: &Tags{Tags: []Tag{{Label: "finance"}}}}}}},
+		},
+		{
+			name: "numbers and booleans for strings",
+			body: `{"workbooks": {"workbook": [{"id": 42, "name": true, "project": {"id": 7}}]}}`,
+			want: QueryWorkbooksResponse{Workbooks: Workbooks{Workbooks: []Workbook{{ID: "42", Name: "true", Project: &Project{ID: "7"}}}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := QueryWorkbooksResponse{}
+			if err := (JSONCodec{}).Unmarshal([]byte(test.body), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("Unmarshal = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	tests := []interface{}{
+		&QueryWorkbooksResponse{
+			Pagination: Pagination{PageNumber: 2, PageSize: 50, TotalAvailable: 51},
+			Workbooks:  Workbooks{Workbooks: []Workbook{{ID: "a", Name: "Sales", ShowTabs: true, Size: 1 << 40, Project: &Project{ID: "p"}}}},
+		},
+		&UpdateSiteRequest{Request: SiteUpdate{UserQuota: Int(10), RevisionHistoryEnabled: Bool(false), WebhooksEnabled: Bool(true), Name: String("Finance")}},
+	}
+	for _, want := range tests {
+		data, err := (JSONCodec{}).Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := reflect.New(reflect.TypeOf(want).Elem()).Interface()
+		if err = (JSONCodec{}).Unmarshal(data, got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s came back as %+v, want %+v", data, got, want)
+		}
+	}
+}
+
+func TestJSONCodecUnmarshalPointers(t *testing.T) {
+	got := UpdateSiteRequest{}
+	body := `{"site": {"userQuota": "10", "storageQuota": "", "revisionHistoryEnabled": "false", "webhooksEnabled": "true"}}`
+	if err := (JSONCodec{}).Unmarshal([]byte(body), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := UpdateSiteRequest{Request: SiteUpdate{UserQuota: Int(10), RevisionHistoryEnabled: Bool(false), WebhooksEnabled: Bool(true)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unmarshal = %+v, want %+v", got.Request, want.Request)
+	}
+}
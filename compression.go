@@ -0,0 +1,60 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const acceptEncodingHeader = "Accept-Encoding"
+const contentEncodingHeader = "Content-Encoding"
+const gzipEncoding = "gzip"
+
+// payloads smaller than this gain too little from compression to be worth it
+const gzipMinRequestSize = 8 << 10
+
+func gzipPayload(payload []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	if _, err := w.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// replaces the body of a gzip encoded response with its decompressed content, the original body is
+// still closed by the caller
+func decompressResponse(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get(contentEncodingHeader), gzipEncoding) {
+		return nil
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		// an empty body, e.g. of a 204
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body = ioutil.NopCloser(gz)
+	resp.Header.Del(contentEncodingHeader)
+	resp.ContentLength = -1
+	return nil
+}
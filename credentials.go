@@ -0,0 +1,198 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const DefaultUsernameEnvVar = "TABLEAU_USERNAME"
+const DefaultPasswordEnvVar = "TABLEAU_PASSWORD"
+
+// SigninCredentials are what a CredentialProvider hands out, either a username and password or a JWT
+type SigninCredentials struct {
+	Username string
+	Password string
+	JWT      string
+	// optional user to impersonate, requires the signing in user to be a server administrator
+	UserIdToImpersonate string
+}
+
+// CredentialProvider supplies credentials when the API signs in through SigninWithCredentialProvider and
+// again whenever the session has to be re-established, so rotated secrets are picked up
+type CredentialProvider interface {
+	Credentials() (SigninCredentials, error)
+}
+
+// CredentialProviderFunc adapts a function, e.g. one reading AWS Secrets Manager, to a CredentialProvider
+type CredentialProviderFunc func() (SigninCredentials, error)
+
+func (f CredentialProviderFunc) Credentials() (SigninCredentials, error) {
+	return f()
+}
+
+// StaticCredentials always returns the same username and password
+type StaticCredentials struct {
+	Username string
+	Password string
+}
+
+func (c StaticCredentials) Credentials() (SigninCredentials, error) {
+	return SigninCredentials{Username: c.Username, Password: c.Password}, nil
+}
+
+// EnvCredentials reads the username and password from environment variables, DefaultUsernameEnvVar and
+// DefaultPasswordEnvVar when the names are left empty
+type EnvCredentials struct {
+	UsernameVar string
+	PasswordVar string
+}
+
+func (c EnvCredentials) Credentials() (SigninCredentials, error) {
+	usernameVar, passwordVar := c.UsernameVar, c.PasswordVar
+	if usernameVar == "" {
+		usernameVar = DefaultUsernameEnvVar
+	}
+	if passwordVar == "" {
+		passwordVar = DefaultPasswordEnvVar
+	}
+	username, password := os.Getenv(usernameVar), os.Getenv(passwordVar)
+	if username == "" || password == "" {
+		return SigninCredentials{}, fmt.Errorf("environment variables %s and %s must both be set", usernameVar, passwordVar)
+	}
+	return SigninCredentials{Username: username, Password: password}, nil
+}
+
+// FileCredentials reads a JSON file of the form {"username": "...", "password": "..."}. The file is read on
+// every call so a rotated secret mounted into a container is picked up.
+type FileCredentials struct {
+	Path string
+}
+
+func (c FileCredentials) Credentials() (SigninCredentials, error) {
+	content, err := ioutil.ReadFile(c.Path)
+	if err != nil {
+		return SigninCredentials{}, err
+	}
+	secret := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{}
+	if err = json.Unmarshal(content, &secret); err != nil {
+		return SigninCredentials{}, fmt.Errorf("reading credentials from %s: %w", c.Path, err)
+	}
+	return SigninCredentials{Username: secret.Username, Password: secret.Password}, nil
+}
+
+// VaultCredentials reads a username and password from a HashiCorp Vault KV secret, version 1 or 2
+type VaultCredentials struct {
+	// e.g. https://vault.example.com:8200
+	Address string
+	Token   string
+	// the API path of the secret, e.g. secret/data/tableau for KV version 2
+	Path string
+	// the keys in the secret, "username" and "password" when empty
+	UsernameKey string
+	PasswordKey string
+	Client      *http.Client
+}
+
+func (c VaultCredentials) Credentials() (SigninCredentials, error) {
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	requestUrl := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(c.Address, "/"), strings.TrimPrefix(c.Path, "/"))
+	req, err := http.NewRequest(GET, requestUrl, nil)
+	if err != nil {
+		return SigninCredentials{}, err
+	}
+	req.Header.Set("X-Vault-Token", c.Token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return SigninCredentials{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return SigninCredentials{}, fmt.Errorf("vault returned %d for %s", resp.StatusCode, c.Path)
+	}
+	secret := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return SigninCredentials{}, err
+	}
+	// KV version 2 nests the secret one level deeper
+	values := secret.Data
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		values = nested
+	}
+	usernameKey, passwordKey := c.UsernameKey, c.PasswordKey
+	if usernameKey == "" {
+		usernameKey = "username"
+	}
+	if passwordKey == "" {
+		passwordKey = "password"
+	}
+	username, _ := values[usernameKey].(string)
+	password, _ := values[passwordKey].(string)
+	if username == "" || password == "" {
+		return SigninCredentials{}, fmt.Errorf("vault secret %s has no %s and %s", c.Path, usernameKey, passwordKey)
+	}
+	return SigninCredentials{Username: username, Password: password}, nil
+}
+
+// ConnectedAppCredentials mints a fresh connected app JWT for every sign-in
+type ConnectedAppCredentials struct {
+	ClientID    string
+	SecretID    string
+	SecretValue string
+	Username    string
+	Scopes      []string
+}
+
+func (c ConnectedAppCredentials) Credentials() (SigninCredentials, error) {
+	jwt, err := NewConnectedAppJWT(c.ClientID, c.SecretID, c.SecretValue, c.Username, c.Scopes, MaxConnectedAppJWTLifetime)
+	if err != nil {
+		return SigninCredentials{}, err
+	}
+	return SigninCredentials{JWT: jwt}, nil
+}
+
+var errNoCredentialProvider = errors.New("no CredentialProvider configured")
+
+// signs in to the site with the credentials of api.CredentialProvider
+func (api *API) SigninWithCredentialProvider(contentUrl string) error {
+	return api.SigninWithCredentialProviderContext(context.Background(), contentUrl)
+}
+
+func (api *API) SigninWithCredentialProviderContext(ctx context.Context, contentUrl string) error {
+	if api.CredentialProvider == nil {
+		return errNoCredentialProvider
+	}
+	credentials, err := api.CredentialProvider.Credentials()
+	if err != nil {
+		return err
+	}
+	if credentials.JWT != "" {
+		return api.SigninWithJWTContext(ctx, credentials.JWT, contentUrl)
+	}
+	return api.SigninContext(ctx, credentials.Username, credentials.Password, contentUrl, credentials.UserIdToImpersonate)
+}
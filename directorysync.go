@@ -0,0 +1,307 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// a user as an external directory (IdP, HR system, SCIM feed) sees it
+type DirectoryUser struct {
+	Name string
+	// empty leaves the site role of an existing user as it is, users without one aren't added to the site
+	SiteRole           SiteRole
+	AuthSetting        AuthSetting
+	IdpConfigurationID string
+}
+
+// a group as an external directory sees it, members are user names
+type DirectoryGroup struct {
+	Name    string
+	Members []string
+}
+
+// the desired state of a site. Only the groups listed here are managed, memberships of other groups are left alone.
+type DirectorySnapshot struct {
+	Users  []DirectoryUser
+	Groups []DirectoryGroup
+}
+
+type SyncOptions struct {
+	// only compute the plan, don't change anything on the site
+	DryRun bool
+	// set site users that are missing from the snapshot to Unlicensed
+	DeactivateMissing bool
+	// remove site users that are missing from the snapshot, takes precedence over DeactivateMissing
+	RemoveMissing bool
+	// the user id receiving the content owned by removed users, removals of content owners fail when empty
+	MapAssetsTo string
+	// create snapshot groups that don't exist on the site, otherwise their memberships are skipped
+	CreateGroups bool
+}
+
+type SyncActionType string
+
+const (
+	SyncCreateGroup     SyncActionType = "create-group"
+	SyncAddUser         SyncActionType = "add-user"
+	SyncChangeRole      SyncActionType = "change-role"
+	SyncAddToGroup      SyncActionType = "add-to-group"
+	SyncRemoveFromGroup SyncActionType = "remove-from-group"
+	SyncDeactivateUser  SyncActionType = "deactivate-user"
+	SyncRemoveUser      SyncActionType = "remove-user"
+)
+
+// a single change needed to converge the site to the snapshot
+type SyncAction struct {
+	Type      SyncActionType
+	UserName  string
+	GroupName string
+	From      SiteRole
+	To        SiteRole
+}
+
+func (a SyncAction) String() string {
+	switch a.Type {
+	case SyncCreateGroup:
+		return fmt.Sprintf("+ group %s", a.GroupName)
+	case SyncAddUser:
+		return fmt.Sprintf("+ user %s (%s)", a.UserName, a.To)
+	case SyncChangeRole, SyncDeactivateUser:
+		return fmt.Sprintf("~ user %s (%s -> %s)", a.UserName, a.From, a.To)
+	case SyncAddToGroup:
+		return fmt.Sprintf("+ member %s of %s", a.UserName, a.GroupName)
+	case SyncRemoveFromGroup:
+		return fmt.Sprintf("- member %s of %s", a.UserName, a.GroupName)
+	case SyncRemoveUser:
+		return fmt.Sprintf("- user %s (%s)", a.UserName, a.From)
+	}
+	return string(a.Type)
+}
+
+// the actions in the order they are applied
+type SyncPlan struct {
+	Actions []SyncAction
+}
+
+// a diff-like listing of the plan, one action per line
+func (p SyncPlan) String() string {
+	lines := make([]string, 0, len(p.Actions))
+	for _, action := range p.Actions {
+		lines = append(lines, action.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// the site as it currently is, keyed by lower cased names since Tableau names are case insensitive
+type directoryState struct {
+	users   map[string]User
+	groups  map[string]Group
+	members map[string]map[string]bool
+	// the signed in user, never deactivated or removed
+	self string
+}
+
+// converges the site's users, site roles and the memberships of the snapshot's groups to the snapshot and
+// returns the actions taken. With options.DryRun nothing is changed and the returned plan shows what would be done.
+func (api *API) SyncDirectory(siteId string, snapshot DirectorySnapshot, options SyncOptions) (SyncPlan, error) {
+	return api.SyncDirectoryContext(context.Background(), siteId, snapshot, options)
+}
+
+func (api *API) SyncDirectoryContext(ctx context.Context, siteId string, snapshot DirectorySnapshot, options SyncOptions) (SyncPlan, error) {
+	for _, user := range snapshot.Users {
+		if err := validateRoles(user.SiteRole, user.AuthSetting); err != nil {
+			return SyncPlan{}, fmt.Errorf("user '%s': %w", user.Name, err)
+		}
+	}
+	state, err := api.loadDirectoryState(ctx, siteId, snapshot)
+	if err != nil {
+		return SyncPlan{}, err
+	}
+	plan := planDirectorySync(snapshot, state, options)
+	if options.DryRun {
+		return plan, nil
+	}
+	return plan, api.applyDirectorySync(ctx, siteId, snapshot, plan, state, options)
+}
+
+func (api *API) loadDirectoryState(ctx context.Context, siteId string, snapshot DirectorySnapshot) (directoryState, error) {
+	state := directoryState{users: map[string]User{}, groups: map[string]Group{}, members: map[string]map[string]bool{}, self: api.CurrentUserID()}
+	users, err := api.QueryUsersOnSiteContext(ctx, siteId)
+	if err != nil {
+		return state, err
+	}
+	for _, user := range users {
+		state.users[strings.ToLower(user.Name)] = user
+	}
+	groups, err := api.QueryGroupsContext(ctx, siteId)
+	if err != nil {
+		return state, err
+	}
+	for _, group := range groups {
+		state.groups[strings.ToLower(group.Name)] = group
+	}
+	for _, directoryGroup := range snapshot.Groups {
+		key := strings.ToLower(directoryGroup.Name)
+		group, ok := state.groups[key]
+		if !ok {
+			continue
+		}
+		groupUsers, err := api.GetUsersInGroupContext(ctx, siteId, group.ID)
+		if err != nil {
+			return state, err
+		}
+		state.members[key] = map[string]bool{}
+		for _, user := range groupUsers {
+			state.members[key][strings.ToLower(user.Name)] = true
+		}
+	}
+	return state, nil
+}
+
+func planDirectorySync(snapshot DirectorySnapshot, state directoryState, options SyncOptions) SyncPlan {
+	plan := SyncPlan{Actions: []SyncAction{}}
+	for _, group := range snapshot.Groups {
+		if _, ok := state.groups[strings.ToLower(group.Name)]; !ok && options.CreateGroups {
+			plan.Actions = append(plan.Actions, SyncAction{Type: SyncCreateGroup, GroupName: group.Name})
+		}
+	}
+
+	wanted := map[string]bool{}
+	for _, user := range snapshot.Users {
+		key := strings.ToLower(user.Name)
+		wanted[key] = true
+		current, ok := state.users[key]
+		switch {
+		case user.SiteRole == "":
+			continue
+		case !ok:
+			plan.Actions = append(plan.Actions, SyncAction{Type: SyncAddUser, UserName: user.Name, To: user.SiteRole})
+		case !strings.EqualFold(string(current.SiteRole), string(user.SiteRole)):
+			plan.Actions = append(plan.Actions, SyncAction{Type: SyncChangeRole, UserName: user.Name, From: current.SiteRole, To: user.SiteRole})
+		}
+	}
+
+	for _, group := range snapshot.Groups {
+		key := strings.ToLower(group.Name)
+		if _, ok := state.groups[key]; !ok && !options.CreateGroups {
+			continue
+		}
+		desired := map[string]bool{}
+		for _, member := range group.Members {
+			desired[strings.ToLower(member)] = true
+			if !state.members[key][strings.ToLower(member)] {
+				plan.Actions = append(plan.Actions, SyncAction{Type: SyncAddToGroup, UserName: member, GroupName: group.Name})
+			}
+		}
+		for _, member := range sortedKeys(state.members[key]) {
+			if !desired[member] {
+				plan.Actions = append(plan.Actions, SyncAction{Type: SyncRemoveFromGroup, UserName: state.users[member].Name, GroupName: group.Name})
+			}
+		}
+	}
+
+	if options.DeactivateMissing || options.RemoveMissing {
+		for _, key := range sortedUserKeys(state.users) {
+			user := state.users[key]
+			if wanted[key] || user.SiteRole == SiteRoleServerAdministrator || (user.ID != "" && user.ID == state.self) {
+				continue
+			}
+			if options.RemoveMissing {
+				plan.Actions = append(plan.Actions, SyncAction{Type: SyncRemoveUser, UserName: user.Name, From: user.SiteRole})
+			} else if user.SiteRole != SiteRoleUnlicensed {
+				plan.Actions = append(plan.Actions, SyncAction{Type: SyncDeactivateUser, UserName: user.Name, From: user.SiteRole, To: SiteRoleUnlicensed})
+			}
+		}
+	}
+	return plan
+}
+
+func (api *API) applyDirectorySync(ctx context.Context, siteId string, snapshot DirectorySnapshot, plan SyncPlan, state directoryState, options SyncOptions) error {
+	directoryUsers := map[string]DirectoryUser{}
+	for _, user := range snapshot.Users {
+		directoryUsers[strings.ToLower(user.Name)] = user
+	}
+	for _, action := range plan.Actions {
+		var err error
+		userKey, groupKey := strings.ToLower(action.UserName), strings.ToLower(action.GroupName)
+		switch action.Type {
+		case SyncCreateGroup:
+			var group Group
+			if group, err = api.createGroup(ctx, siteId, GroupRequest{Name: action.GroupName}); err == nil {
+				state.groups[groupKey] = group
+			}
+		case SyncAddUser:
+			var user User
+			directoryUser := directoryUsers[userKey]
+			newUser := User{Name: action.UserName, SiteRole: action.To, AuthSetting: directoryUser.AuthSetting, IdpConfigurationID: directoryUser.IdpConfigurationID}
+			if user, err = api.addUserToSite(ctx, siteId, newUser); err == nil {
+				state.users[userKey] = user
+			}
+		case SyncChangeRole, SyncDeactivateUser:
+			_, err = api.updateUser(ctx, siteId, state.users[userKey].ID, User{SiteRole: action.To})
+		case SyncAddToGroup:
+			user, ok := state.users[userKey]
+			if !ok {
+				err = fmt.Errorf("User Named '%s' %w", action.UserName, ErrNotFound)
+				break
+			}
+			_, err = api.AddUserToGroupContext(ctx, siteId, state.groups[groupKey].ID, user.ID)
+		case SyncRemoveFromGroup:
+			err = api.RemoveUserFromGroupContext(ctx, siteId, state.groups[groupKey].ID, state.users[userKey].ID)
+		case SyncRemoveUser:
+			if err = api.RemoveUserFromSiteMappingAssetsContext(ctx, siteId, state.users[userKey].ID, options.MapAssetsTo); err == nil {
+				delete(state.users, userKey)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", action, err)
+		}
+	}
+	return nil
+}
+
+// map iteration order is random, sorting keeps plans stable between runs
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedUserKeys(users map[string]User) []string {
+	keys := make([]string, 0, len(users))
+	for key := range users {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DesiredUser is a user as ReconcileUsers should leave it on the site
+type DesiredUser = DirectoryUser
+
+// adds the desired users missing from the site and corrects the site roles of the others. Users that aren't
+// desired are left alone unless options.DeactivateMissing or options.RemoveMissing is set, server administrators
+// and the signed in user are always kept. With options.DryRun the returned plan shows what would be done.
+func (api *API) ReconcileUsers(siteId string, desired []DesiredUser, options SyncOptions) (SyncPlan, error) {
+	return api.ReconcileUsersContext(context.Background(), siteId, desired, options)
+}
+
+func (api *API) ReconcileUsersContext(ctx context.Context, siteId string, desired []DesiredUser, options SyncOptions) (SyncPlan, error) {
+	return api.SyncDirectoryContext(ctx, siteId, DirectorySnapshot{Users: desired}, options)
+}
@@ -0,0 +1,155 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanDirectorySync(t *testing.T) {
+	state := directoryState{
+		users: map[string]User{
+			"admin":  {ID: "1", Name: "admin", SiteRole: SiteRoleServerAdministrator},
+			"self":   {ID: "2", Name: "self", SiteRole: SiteRoleSiteAdminCreator},
+			"alice":  {ID: "3", Name: "Alice", SiteRole: SiteRoleViewer},
+			"bob":    {ID: "4", Name: "bob", SiteRole: SiteRoleExplorer},
+			"carol":  {ID: "5", Name: "carol", SiteRole: SiteRoleCreator},
+			"gone":   {ID: "6", Name: "gone", SiteRole: SiteRoleUnlicensed},
+			"norole": {ID: "7", Name: "norole", SiteRole: SiteRoleViewer},
+		},
+		groups:  map[string]Group{"finance": {ID: "g1", Name: "Finance"}},
+		members: map[string]map[string]bool{"finance": {"alice": true, "carol": true}},
+		self:    "2",
+	}
+	snapshot := DirectorySnapshot{
+		Users: []DirectoryUser{
+			{Name: "alice", SiteRole: SiteRoleViewer},
+			{Name: "Bob", SiteRole: SiteRoleCreator},
+			{Name: "dave", SiteRole: SiteRoleExplorer},
+			{Name: "norole"},
+			{Name: "erin"},
+		},
+		Groups: []DirectoryGroup{
+			{Name: "finance", Members: []string{"Alice", "bob"}},
+			{Name: "Sales", Members: []string{"dave"}},
+		},
+	}
+	unchanged := []SyncAction{
+		{Type: SyncChangeRole, UserName: "Bob", From: SiteRoleExplorer, To: SiteRoleCreator},
+		{Type: SyncAddUser, UserName: "dave", To: SiteRoleExplorer},
+		{Type: SyncAddToGroup, UserName: "bob", GroupName: "finance"},
+		{Type: SyncRemoveFromGroup, UserName: "carol", GroupName: "finance"},
+	}
+	tests := []struct {
+		name    string
+		options SyncOptions
+		want    []SyncAction
+	}{
+		{
+			name:    "missing users are left alone",
+			options: SyncOptions{},
+			want:    unchanged,
+		},
+		{
+			name:    "creates missing groups",
+			options: SyncOptions{CreateGroups: true},
+			want: append(append([]SyncAction{{Type: SyncCreateGroup, GroupName: "Sales"}}, unchanged...),
+				SyncAction{Type: SyncAddToGroup, UserName: "dave", GroupName: "Sales"}),
+		},
+		{
+			// admin and self are missing but kept, gone is unlicensed already
+			name:    "deactivates missing users",
+			options: SyncOptions{DeactivateMissing: true},
+			want: append(append([]SyncAction{}, unchanged...),
+				SyncAction{Type: SyncDeactivateUser, UserName: "carol", From: SiteRoleCreator, To: SiteRoleUnlicensed}),
+		},
+		{
+			name:    "removes missing users",
+			options: SyncOptions{DeactivateMissing: true, RemoveMissing: true},
+			want: append(append([]SyncAction{}, unchanged...),
+				SyncAction{Type: SyncRemoveUser, UserName: "carol", From: SiteRoleCreator},
+				SyncAction{Type: SyncRemoveUser, UserName: "gone", From: SiteRoleUnlicensed}),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan := planDirectorySync(snapshot, state, test.options)
+			if !reflect.DeepEqual(plan.Actions, test.want) {
+				t.Fatalf("plan:\n%s\nwant:\n%s", plan, SyncPlan{Actions: test.want})
+			}
+		})
+	}
+}
+
+func TestPlanDirectorySyncInSync(t *testing.T) {
+	state := directoryState{
+		users:   map[string]User{"alice": {ID: "1", Name: "alice", SiteRole: SiteRoleViewer}},
+		groups:  map[string]Group{"finance": {ID: "g1", Name: "Finance"}},
+		members: map[string]map[string]bool{"finance": {"alice": true}},
+	}
+	snapshot := DirectorySnapshot{
+		Users:  []DirectoryUser{{Name: "Alice", SiteRole: "viewer"}},
+		Groups: []DirectoryGroup{{Name: "Finance", Members: []string{"ALICE"}}},
+	}
+	if plan := planDirectorySync(snapshot, state, SyncOptions{RemoveMissing: true}); len(plan.Actions) != 0 {
+		t.Fatalf("a site matching the snapshot planned:\n%s", plan)
+	}
+}
+
+func TestReconcileUsersDryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("a dry run sent %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/users"):
+			fmt.Fprint(w, `<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="3"/><users>`+
+				`<user id="1" name="admin" siteRole="ServerAdministrator"/><user id="2" name="alice" siteRole="Viewer"/>`+
+				`<user id="3" name="bob" siteRole="Creator"/></users></tsResponse>`)
+		case strings.HasSuffix(r.URL.Path, "/groups"):
+			fmt.Fprint(w, `<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="0"/><groups/></tsResponse>`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+
+	desired := []DesiredUser{{Name: "Alice", SiteRole: SiteRoleExplorer}, {Name: "carol", SiteRole: SiteRoleViewer}}
+	plan, err := api.ReconcileUsers("site", desired, SyncOptions{DryRun: true, DeactivateMissing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SyncAction{
+		{Type: SyncChangeRole, UserName: "Alice", From: SiteRoleViewer, To: SiteRoleExplorer},
+		{Type: SyncAddUser, UserName: "carol", To: SiteRoleViewer},
+		{Type: SyncDeactivateUser, UserName: "bob", From: SiteRoleCreator, To: SiteRoleUnlicensed},
+	}
+	if !reflect.DeepEqual(plan.Actions, want) {
+		t.Fatalf("plan:\n%s\nwant:\n%s", plan, SyncPlan{Actions: want})
+	}
+
+	if _, err = api.ReconcileUsers("site", []DesiredUser{{Name: "dave", SiteRole: "Owner"}}, SyncOptions{DryRun: true}); !errors.Is(err, ErrInvalidSiteRole) {
+		t.Fatalf("an invalid site role returned %v, want ErrInvalidSiteRole", err)
+	}
+}
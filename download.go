@@ -0,0 +1,153 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// passed as the result of a request to copy a successful response's body to w instead of decoding it
+type streamTo struct {
+	w       io.Writer
+	written int64
+}
+
+// a stream that failed halfway can't be retried, w already holds part of the body
+func streamStarted(result interface{}) bool {
+	stream, ok := result.(*streamTo)
+	return ok && stream.written > 0
+}
+
+func (api *API) stream(stream *streamTo, resp *http.Response, requestUrl, method string, start time.Time) error {
+	written, err := io.Copy(stream.w, resp.Body)
+	stream.written += written
+	api.observeRequest(requestUrl, method, resp.StatusCode, time.Since(start), err)
+	api.loggerFor(resp.Request.Context()).Debugf("t4g Response: %d bytes streamed", written)
+	if err != nil {
+		return err
+	}
+	api.touchSession()
+	return nil
+}
+
+// http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Download_Datasource%3FTocPath%3DAPI%2520Reference%7C_____34
+// streams the .tdsx (or .tds) into w without holding it in memory and returns the number of bytes written
+func (api *API) DownloadDatasourceTo(siteId, datasourceId string, includeExtract bool, w io.Writer) (int64, error) {
+	return api.DownloadDatasourceToContext(context.Background(), siteId, datasourceId, includeExtract, w)
+}
+
+func (api *API) DownloadDatasourceToContext(ctx context.Context, siteId, datasourceId string, includeExtract bool, w io.Writer) (int64, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources/%s/content?includeExtract=%v", api.Server, api.Version, siteId, datasourceId, includeExtract)
+	return api.download(ctx, requestUrl, w)
+}
+
+// streams the datasource into the file at path, which is removed again when the download fails
+func (api *API) DownloadDatasourceToFile(siteId, datasourceId string, includeExtract bool, path string) error {
+	return api.DownloadDatasourceToFileContext(context.Background(), siteId, datasourceId, includeExtract, path)
+}
+
+func (api *API) DownloadDatasourceToFileContext(ctx context.Context, siteId, datasourceId string, includeExtract bool, path string) error {
+	return downloadToFile(path, func(w io.Writer) (int64, error) {
+		return api.DownloadDatasourceToContext(ctx, siteId, datasourceId, includeExtract, w)
+	})
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#download_workbook
+// streams the .twbx (or .twb) into w without holding it in memory and returns the number of bytes written
+func (api *API) DownloadWorkbookTo(siteId, workbookId string, includeExtract bool, w io.Writer) (int64, error) {
+	return api.DownloadWorkbookToContext(context.Background(), siteId, workbookId, includeExtract, w)
+}
+
+func (api *API) DownloadWorkbookToContext(ctx context.Context, siteId, workbookId string, includeExtract bool, w io.Writer) (int64, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s/content?includeExtract=%v", api.Server, api.Version, siteId, workbookId, includeExtract)
+	return api.download(ctx, requestUrl, w)
+}
+
+// streams the workbook into the file at path, which is removed again when the download fails
+func (api *API) DownloadWorkbookToFile(siteId, workbookId string, includeExtract bool, path string) error {
+	return api.DownloadWorkbookToFileContext(context.Background(), siteId, workbookId, includeExtract, path)
+}
+
+func (api *API) DownloadWorkbookToFileContext(ctx context.Context, siteId, workbookId string, includeExtract bool, path string) error {
+	return downloadToFile(path, func(w io.Writer) (int64, error) {
+		return api.DownloadWorkbookToContext(ctx, siteId, workbookId, includeExtract, w)
+	})
+}
+
+// streams the .twbx (or .twb) into w and returns the file name the server gave it, e.g. Sales.twbx
+func (api *API) DownloadWorkbook(siteId, workbookId string, w io.Writer, includeExtract bool) (string, error) {
+	return api.DownloadWorkbookContext(context.Background(), siteId, workbookId, w, includeExtract)
+}
+
+func (api *API) DownloadWorkbookContext(ctx context.Context, siteId, workbookId string, w io.Writer, includeExtract bool) (string, error) {
+	response := &Response{}
+	_, err := api.DownloadWorkbookToContext(WithResponse(ctx, response), siteId, workbookId, includeExtract, w)
+	if outer, ok := ctx.Value(responseKey{}).(*Response); ok && outer != nil {
+		*outer = *response
+	}
+	if err != nil {
+		return "", err
+	}
+	return contentDispositionFilename(response.Header.Get("Content-Disposition")), nil
+}
+
+// Tableau answers downloads with e.g. Content-Disposition: name="tableau_workbook"; filename="Sales.twbx",
+// which lacks the disposition type the mime package expects
+func contentDispositionFilename(header string) string {
+	if header == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		if _, params, err = mime.ParseMediaType("attachment; " + header); err != nil {
+			return ""
+		}
+	}
+	if params["filename"] == "" {
+		return ""
+	}
+	// the name is joined to a directory by callers, so it must not lead out of it
+	name := filepath.Base(params["filename"])
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return ""
+	}
+	return name
+}
+
+func (api *API) download(ctx context.Context, requestUrl string, w io.Writer) (int64, error) {
+	headers := make(map[string]string)
+	stream := &streamTo{w: w}
+	_, err := api.makeRequestGetBody(withOperation(ctx, operationDownload), requestUrl, GET, nil, stream, headers)
+	return stream.written, err
+}
+
+func downloadToFile(path string, download func(w io.Writer) (int64, error)) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = download(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import "testing"
+
+func TestContentDispositionFilename(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{`name="tableau_workbook"; filename="Sales.twbx"`, "Sales.twbx"},
+		{`attachment; filename="Sales.tdsx"`, "Sales.tdsx"},
+		{`attachment; filename="../../etc/passwd"`, "passwd"},
+		{`attachment; filename=".."`, ""},
+		{`attachment; filename="/"`, ""},
+		{`name="tableau_workbook"`, ""},
+		{`attachment`, ""},
+		{``, ""},
+		{`;;;`, ""},
+	}
+	for _, test := range tests {
+		if got := contentDispositionFilename(test.header); got != test.want {
+			t.Errorf("contentDispositionFilename(%q) = %q, want %q", test.header, got, test.want)
+		}
+	}
+}
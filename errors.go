@@ -0,0 +1,130 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// errors to branch on with errors.Is, the errors returned by requests match them by HTTP status and Tableau
+// error code, e.g. errors.Is(err, ErrNotFound) for a TError with code 404004 or a 404 StatusError
+var ErrNotFound = errors.New("Not Found")
+var ErrUnauthorized = errors.New("Unauthorized")
+var ErrSessionExpired = errors.New("Session Expired")
+var ErrConcurrencyLimit = errors.New("Concurrency Limit Reached")
+var ErrPayloadTooLarge = errors.New("Payload Too Large")
+var ErrConflict = errors.New("Conflict")
+
+func statusIs(status int, target error) bool {
+	switch target {
+	case ErrNotFound:
+		return status == http.StatusNotFound
+	case ErrUnauthorized:
+		return status == http.StatusUnauthorized
+	case ErrConcurrencyLimit:
+		return status == http.StatusTooManyRequests
+	case ErrPayloadTooLarge:
+		return status == http.StatusRequestEntityTooLarge
+	case ErrConflict:
+		return status == http.StatusConflict
+	}
+	return false
+}
+
+func (e *StatusError) Is(target error) bool {
+	return statusIs(e.Code, target)
+}
+
+// tableau error codes start with the http status, e.g. 404004
+func (t TError) Is(target error) bool {
+	if target == ErrSessionExpired {
+		return t.Code == tokenExpiredErrorCode
+	}
+	if len(t.Code) < 3 {
+		return false
+	}
+	status, err := strconv.Atoi(t.Code[:3])
+	return err == nil && statusIs(status, target)
+}
+
+// TableauError is returned for every response with an error status. It carries the error the server described
+// in the body when there is one, gateways and load balancers often answer with html instead. errors.As turns
+// it into a TError when the server sent a code and always into a *StatusError.
+type TableauError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	// the server's error code, e.g. 404004, with its summary and detail
+	Code    string
+	Summary string
+	Detail  string
+	// how long the server asked to wait before trying again, from the Retry-After header of a 429 or 503
+	RetryAfter time.Duration
+}
+
+func (e *TableauError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%d - %s.  Request URL was: %s", e.StatusCode, http.StatusText(e.StatusCode), e.URL)
+	}
+	return fmt.Sprintf("Code:%s, Summary:%s, Detail:%s (%s %s answered %d)", e.Code, e.Summary, e.Detail, e.Method, e.URL, e.StatusCode)
+}
+
+func (e *TableauError) Is(target error) bool {
+	return statusIs(e.StatusCode, target) || (e.Code != "" && e.tError().Is(target))
+}
+
+func (e *TableauError) As(target interface{}) bool {
+	switch typed := target.(type) {
+	case *TError:
+		if e.Code == "" {
+			return false
+		}
+		*typed = e.tError()
+		return true
+	case **StatusError:
+		*typed = &StatusError{Code: e.StatusCode, Msg: http.StatusText(e.StatusCode), URL: e.URL}
+		return true
+	}
+	return false
+}
+
+func (e *TableauError) tError() TError {
+	return TError{Code: e.Code, Summary: e.Summary, Detail: e.Detail}
+}
+
+// the wait a rate limited or unavailable server asked for, false when the error doesn't carry one
+func RetryAfter(err error) (time.Duration, bool) {
+	var tableauErr *TableauError
+	if errors.As(err, &tableauErr) && tableauErr.RetryAfter > 0 {
+		return tableauErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// the error for a response with an error status, from the error the body describes when it can be decoded
+func (api *API) responseError(method, requestUrl string, resp *http.Response, body []byte) *TableauError {
+	tableauErr := &TableauError{StatusCode: resp.StatusCode, Method: method, URL: requestUrl}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		tableauErr.RetryAfter, _ = parseRetryAfter(resp.Header.Get(retryAfterHeader), time.Now())
+	}
+	errorResponse := ErrorResponse{}
+	if err := api.codec().Unmarshal(body, &errorResponse); err == nil {
+		tableauErr.Code = errorResponse.Error.Code
+		tableauErr.Summary = errorResponse.Error.Summary
+		tableauErr.Detail = errorResponse.Error.Detail
+	}
+	return tableauErr
+}
@@ -0,0 +1,96 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStatusIs(t *testing.T) {
+	tests := []struct {
+		status int
+		target error
+		want   bool
+	}{
+		{404, ErrNotFound, true},
+		{401, ErrUnauthorized, true},
+		{429, ErrConcurrencyLimit, true},
+		{413, ErrPayloadTooLarge, true},
+		{409, ErrConflict, true},
+		{400, ErrNotFound, false},
+		{404, ErrUnauthorized, false},
+		// an expired session is only told apart by its Tableau error code
+		{401, ErrSessionExpired, false},
+	}
+	for _, test := range tests {
+		if got := statusIs(test.status, test.target); got != test.want {
+			t.Errorf("statusIs(%d, %v) = %v, want %v", test.status, test.target, got, test.want)
+		}
+	}
+}
+
+// the errors of requests are matched through the request id and version fallback wrappers
+func TestTableauErrorWrapped(t *testing.T) {
+	notFound := &TableauError{StatusCode: 404, Method: GET, URL: "https://tableau/api/3.4/sites/s/flows", Code: "404004", Summary: "Not Found"}
+	expired := &TableauError{StatusCode: 401, Method: GET, URL: "https://tableau/api/3.4/sites/s/flows", Code: tokenExpiredErrorCode}
+	html := &TableauError{StatusCode: 404, Method: GET, URL: "https://tableau/api/3.4/sites/s/flows"}
+	unavailable := func(err error) error {
+		return &EndpointUnavailableError{URL: "https://tableau/api/3.4/sites/s/flows", Version: "3.4", ServerVersion: "3.3", Err: err}
+	}
+	requestFailed := func(err error) error {
+		return &RequestError{RequestID: "0123", Err: err}
+	}
+	tests := []struct {
+		name   string
+		err    error
+		is     []error
+		isNot  []error
+		code   string
+		status int
+	}{
+		{"plain", notFound, []error{ErrNotFound}, []error{ErrUnauthorized, ErrEndpointUnavailable}, "404004", 404},
+		{"with a request id", requestFailed(notFound), []error{ErrNotFound}, []error{ErrEndpointUnavailable}, "404004", 404},
+		{"endpoint unavailable", unavailable(notFound), []error{ErrNotFound, ErrEndpointUnavailable}, []error{ErrConflict}, "404004", 404},
+		{"both", requestFailed(unavailable(notFound)), []error{ErrNotFound, ErrEndpointUnavailable}, nil, "404004", 404},
+		{"fmt wrapped", fmt.Errorf("publishing: %w", requestFailed(notFound)), []error{ErrNotFound}, nil, "404004", 404},
+		{"session expired", requestFailed(expired), []error{ErrSessionExpired, ErrUnauthorized}, []error{ErrNotFound}, tokenExpiredErrorCode, 401},
+		{"without an error body", requestFailed(unavailable(html)), []error{ErrNotFound, ErrEndpointUnavailable}, nil, "", 404},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, target := range test.is {
+				if !errors.Is(test.err, target) {
+					t.Errorf("errors.Is(%v) is false", target)
+				}
+			}
+			for _, target := range test.isNot {
+				if errors.Is(test.err, target) {
+					t.Errorf("errors.Is(%v) is true", target)
+				}
+			}
+			tErr := TError{}
+			if ok := errors.As(test.err, &tErr); ok != (test.code != "") || tErr.Code != test.code {
+				t.Errorf("errors.As TError = %v with code %q, want code %q", ok, tErr.Code, test.code)
+			}
+			var statusErr *StatusError
+			if !errors.As(test.err, &statusErr) || statusErr.Code != test.status {
+				t.Errorf("errors.As *StatusError = %+v, want status %d", statusErr, test.status)
+			}
+			var tableauErr *TableauError
+			if !errors.As(test.err, &tableauErr) || tableauErr.StatusCode != test.status {
+				t.Errorf("errors.As *TableauError = %+v, want status %d", tableauErr, test.status)
+			}
+		})
+	}
+}
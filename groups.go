@@ -0,0 +1,281 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// every site has this group, all of its users are members
+const AllUsersGroupName = "All Users"
+
+// ErrDeleteAllUsersGroup is returned by DeleteGroup when API.ProtectAllUsersGroup is set and the group is All Users
+var ErrDeleteAllUsersGroup = errors.New("refusing to delete the All Users group")
+
+// returns every group of the site, filters narrow them down on the server,
+// e.g. QueryGroups(siteId, Filter().Eq("name", "Finance"))
+func (api *API) QueryGroups(siteId string, opts ...QueryOption) ([]Group, error) {
+	return api.QueryGroupsContext(context.Background(), siteId, opts...)
+}
+
+func (api *API) QueryGroupsContext(ctx context.Context, siteId string, opts ...QueryOption) ([]Group, error) {
+	totalAvailable := 1
+	groups := []Group{}
+	for i := 1; len(groups) < totalAvailable; i++ {
+		response, err := api.QueryGroupsByPageContext(ctx, siteId, i, opts...)
+		if err != nil {
+			return groups, err
+		}
+		groups = append(groups, response.Groups.Groups...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return groups, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#query_groups
+func (api *API) QueryGroupsByPage(siteId string, pageNum int, opts ...QueryOption) (QueryGroupsResponse, error) {
+	return api.QueryGroupsByPageContext(context.Background(), siteId, pageNum, opts...)
+}
+
+func (api *API) QueryGroupsByPageContext(ctx context.Context, siteId string, pageNum int, opts ...QueryOption) (QueryGroupsResponse, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/groups", api.Server, api.Version, siteId), pageNum, opts)
+	headers := make(map[string]string)
+	response := QueryGroupsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response, err
+}
+
+// returns every member of the group
+func (api *API) GetUsersInGroup(siteId, groupId string, opts ...QueryOption) ([]User, error) {
+	return api.GetUsersInGroupContext(context.Background(), siteId, groupId, opts...)
+}
+
+func (api *API) GetUsersInGroupContext(ctx context.Context, siteId, groupId string, opts ...QueryOption) ([]User, error) {
+	totalAvailable := 1
+	users := []User{}
+	for i := 1; len(users) < totalAvailable; i++ {
+		response, err := api.GetUsersInGroupByPageContext(ctx, siteId, groupId, i, opts...)
+		if err != nil {
+			return users, err
+		}
+		users = append(users, response.Users.Users...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return users, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#get_users_in_group
+func (api *API) GetUsersInGroupByPage(siteId, groupId string, pageNum int, opts ...QueryOption) (QueryUsersResponse, error) {
+	return api.GetUsersInGroupByPageContext(context.Background(), siteId, groupId, pageNum, opts...)
+}
+
+func (api *API) GetUsersInGroupByPageContext(ctx context.Context, siteId, groupId string, pageNum int, opts ...QueryOption) (QueryUsersResponse, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/groups/%s/users", api.Server, api.Version, siteId, groupId), pageNum, opts)
+	headers := make(map[string]string)
+	response := QueryUsersResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response, err
+}
+
+// returns the groups the user is a member of, including All Users
+func (api *API) GetGroupsForUser(siteId, userId string, opts ...QueryOption) ([]Group, error) {
+	return api.GetGroupsForUserContext(context.Background(), siteId, userId, opts...)
+}
+
+func (api *API) GetGroupsForUserContext(ctx context.Context, siteId, userId string, opts ...QueryOption) ([]Group, error) {
+	totalAvailable := 1
+	groups := []Group{}
+	for i := 1; len(groups) < totalAvailable; i++ {
+		response, err := api.GetGroupsForUserByPageContext(ctx, siteId, userId, i, opts...)
+		if err != nil {
+			return groups, err
+		}
+		groups = append(groups, response.Groups.Groups...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return groups, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#get_groups_for_a_user
+func (api *API) GetGroupsForUserByPage(siteId, userId string, pageNum int, opts ...QueryOption) (QueryGroupsResponse, error) {
+	return api.GetGroupsForUserByPageContext(context.Background(), siteId, userId, pageNum, opts...)
+}
+
+func (api *API) GetGroupsForUserByPageContext(ctx context.Context, siteId, userId string, pageNum int, opts ...QueryOption) (QueryGroupsResponse, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/users/%s/groups", api.Server, api.Version, siteId, userId), pageNum, opts)
+	headers := make(map[string]string)
+	response := QueryGroupsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response, err
+}
+
+// creates a local group, or imports a group from Active Directory when group.Import is set
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#create_group
+func (api *API) CreateGroup(siteId string, group GroupRequest) (Group, error) {
+	return api.CreateGroupContext(context.Background(), siteId, group)
+}
+
+func (api *API) CreateGroupContext(ctx context.Context, siteId string, group GroupRequest) (Group, error) {
+	if group.Import != nil && group.Import.Source == "" {
+		imported := *group.Import
+		imported.Source = GroupImportSourceActiveDirectory
+		group.Import = &imported
+	}
+	return api.createGroup(ctx, siteId, group)
+}
+
+func (api *API) createGroup(ctx context.Context, siteId string, group GroupRequest) (Group, error) {
+	if err := group.validate(); err != nil {
+		return Group{}, err
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/groups", api.Server, api.Version, siteId)
+	payload, headers, err := api.encodeRequest(CreateGroupRequest{Request: group})
+	if err != nil {
+		return Group{}, err
+	}
+	retval := CreateGroupResponse{}
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	return retval.Group, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#add_user_to_group
+func (api *API) AddUserToGroup(siteId, groupId, userId string) (User, error) {
+	return api.AddUserToGroupContext(context.Background(), siteId, groupId, userId)
+}
+
+func (api *API) AddUserToGroupContext(ctx context.Context, siteId, groupId, userId string) (User, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/groups/%s/users", api.Server, api.Version, siteId, groupId)
+	payload, headers, err := api.encodeRequest(AddUserRequest{Request: User{ID: userId}})
+	if err != nil {
+		return User{}, err
+	}
+	retval := UserResponse{}
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	return retval.User, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#remove_user_to_group
+func (api *API) RemoveUserFromGroup(siteId, groupId, userId string) error {
+	return api.RemoveUserFromGroupContext(context.Background(), siteId, groupId, userId)
+}
+
+func (api *API) RemoveUserFromGroupContext(ctx context.Context, siteId, groupId, userId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/groups/%s/users/%s", api.Server, api.Version, siteId, groupId, userId)
+	return api.delete(ctx, requestUrl)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#delete_group
+func (api *API) DeleteGroup(siteId, groupId string) error {
+	return api.DeleteGroupContext(context.Background(), siteId, groupId)
+}
+
+func (api *API) DeleteGroupContext(ctx context.Context, siteId, groupId string) error {
+	if api.ProtectAllUsersGroup {
+		groups, err := api.QueryGroupsContext(ctx, siteId, Filter().Eq("name", AllUsersGroupName))
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			if group.ID == groupId && group.Name == AllUsersGroupName {
+				return ErrDeleteAllUsersGroup
+			}
+		}
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/groups/%s", api.Server, api.Version, siteId, groupId)
+	return api.delete(ctx, requestUrl)
+}
+
+// imports the Active Directory group domainName\groupName as a background job, poll it with QueryJob.
+// grantLicenseMode is GrantLicenseOnLogin, GrantLicenseOnSync or empty to leave the members unlicensed,
+// siteRole is the role granted with the license.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#create_group
+func (api *API) ImportGroup(siteId, domainName, groupName, grantLicenseMode string, siteRole SiteRole) (Job, error) {
+	return api.ImportGroupContext(context.Background(), siteId, domainName, groupName, grantLicenseMode, siteRole)
+}
+
+func (api *API) ImportGroupContext(ctx context.Context, siteId, domainName, groupName, grantLicenseMode string, siteRole SiteRole) (Job, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/groups?asJob=true", api.Server, api.Version, siteId)
+	group := GroupRequest{Name: groupName, Import: &GroupImport{
+		Source:           GroupImportSourceActiveDirectory,
+		DomainName:       domainName,
+		GrantLicenseMode: grantLicenseMode,
+		SiteRole:         siteRole,
+	}}
+	if err := group.validate(); err != nil {
+		return Job{}, err
+	}
+	payload, headers, err := api.encodeRequest(CreateGroupRequest{Request: group})
+	if err != nil {
+		return Job{}, err
+	}
+	retval := QueryJobResponse{}
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	return retval.Job, err
+}
+
+// synchronizes the members of an imported group with Active Directory now rather than on the server's schedule,
+// group is as returned by QueryGroups. The sync runs as a background job, poll it with QueryJob.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#update_group
+func (api *API) SyncGroup(siteId string, group Group) (Job, error) {
+	return api.SyncGroupContext(context.Background(), siteId, group)
+}
+
+func (api *API) SyncGroupContext(ctx context.Context, siteId string, group Group) (Job, error) {
+	if !group.Imported() {
+		return Job{}, fmt.Errorf("group '%s' isn't imported from Active Directory", group.Name)
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/groups/%s?asJob=true", api.Server, api.Version, siteId, group.ID)
+	payload, headers, err := api.encodeRequest(UpdateGroupRequest{Request: GroupRequest{Name: group.Name, Import: group.Import}})
+	if err != nil {
+		return Job{}, err
+	}
+	retval := QueryJobResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.Job, err
+}
+
+// starts a sync of every imported group of the site, returning the jobs started before the first failure
+func (api *API) SyncImportedGroups(siteId string) ([]Job, error) {
+	return api.SyncImportedGroupsContext(context.Background(), siteId)
+}
+
+func (api *API) SyncImportedGroupsContext(ctx context.Context, siteId string) ([]Job, error) {
+	groups, err := api.QueryGroupsContext(ctx, siteId)
+	if err != nil {
+		return nil, err
+	}
+	jobs := []Job{}
+	for _, group := range groups {
+		if !group.Imported() {
+			continue
+		}
+		job, err := api.SyncGroupContext(ctx, siteId, group)
+		if err != nil {
+			return jobs, err
+		}
+		api.loggerFor(ctx).Debugf("Started sync job %s of group %s on siteId %s", job.ID, group.Name, siteId)
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (group GroupRequest) validate() error {
+	if err := validateRoles(group.MinimumSiteRole, ""); err != nil {
+		return err
+	}
+	if group.Import != nil {
+		return validateRoles(group.Import.SiteRole, "")
+	}
+	return nil
+}
@@ -3,11 +3,14 @@ package tableau4go
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -16,66 +19,264 @@ var (
 	readWriteTimeout = 20 * time.Second
 )
 
-func timeoutDialer(cTimeout time.Duration, rwTimeout time.Duration) func(network, address string) (net.Conn, error) {
-	return func(netw, addr string) (net.Conn, error) {
-		conn, err := net.DialTimeout(netw, addr, cTimeout)
+// TLSOptions configures the TLS connections to the server, set it on API.TLS
+type TLSOptions struct {
+	// client certificate and key, either as PEM files or as PEM data
+	CertFile string
+	KeyFile  string
+	CertPEM  []byte
+	KeyPEM   []byte
+	// CAs the server's certificate is verified against instead of the system roots, as a pool, a PEM file
+	// or PEM data
+	RootCAs *x509.CertPool
+	CAFile  string
+	CAPEM   []byte
+	// the oldest TLS version accepted, e.g. tls.VersionTLS13, Go's default when zero
+	MinVersion uint16
+	// the name the server's certificate is verified for when it differs from the host in Server
+	ServerName string
+}
+
+// TLSOptionsFromEnv reads the variables apps used to set for NewTimeoutClient:
+// atscale_http_sslcert - location of the http ssl cert
+// atscale_http_sslkey - location of the http ssl key
+// atscale_ca_file - location of the CA the server's certificate is verified against
+func TLSOptionsFromEnv() TLSOptions {
+	options := TLSOptions{}
+	if certLocation, keyLocation := os.Getenv("atscale_http_sslcert"), os.Getenv("atscale_http_sslkey"); len(certLocation) > 0 && len(keyLocation) > 0 {
+		options.CertFile, options.KeyFile = certLocation, keyLocation
+		options.CAFile = os.Getenv("atscale_ca_file")
+	}
+	return options
+}
+
+func (options TLSOptions) config(insecure bool) (*tls.Config, error) {
+	//nolint:gosec // skipping verification is an explicit opt-out, see API.AllowInsecureTLS
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure, MinVersion: options.MinVersion, ServerName: options.ServerName}
+	certPEM, keyPEM := options.CertPEM, options.KeyPEM
+	if len(options.CertFile) > 0 && len(options.KeyFile) > 0 {
+		var err error
+		if certPEM, err = ioutil.ReadFile(options.CertFile); err != nil {
+			return nil, err
+		}
+		if keyPEM, err = ioutil.ReadFile(options.KeyFile); err != nil {
+			return nil, err
+		}
+	}
+	if len(certPEM) > 0 && len(keyPEM) > 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
 		if err != nil {
+			return nil, fmt.Errorf("loading the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	tlsConfig.RootCAs = options.RootCAs
+	caPEM := options.CAPEM
+	if len(options.CAFile) > 0 {
+		var err error
+		if caPEM, err = ioutil.ReadFile(options.CAFile); err != nil {
 			return nil, err
 		}
-
-		if rwTimeout > 0 {
-			if err = conn.SetDeadline(time.Now().Add(rwTimeout)); err != nil {
-				return nil, err
-			}
+	}
+	if len(caPEM) > 0 {
+		if tlsConfig.RootCAs == nil {
+			tlsConfig.RootCAs = x509.NewCertPool()
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("no CA certificates found in the PEM data")
 		}
-		return conn, nil
 	}
+	return tlsConfig, nil
 }
 
-// apps will set two OS variables:
-// atscale_http_sslcert - location of the http ssl cert
-// atscale_http_sslkey - location of the http ssl key
+// NewTimeoutClient verifies the server's certificate, with useClientCerts it takes the client certificate
+// and CA from the environment, see TLSOptionsFromEnv. rwTimeout bounds the wait for the response headers,
+// reading the body isn't limited so long downloads aren't cut off while data is flowing. When the certificate
+// files can't be loaded every request made with the client fails with the error.
+//
+// Deprecated: set API.TLS, and HTTPClient for a custom client, instead of configuring the environment
 func NewTimeoutClient(cTimeout time.Duration, rwTimeout time.Duration, useClientCerts bool) *http.Client {
-	certLocation := os.Getenv("atscale_http_sslcert")
-	keyLocation := os.Getenv("atscale_http_sslkey")
-	caFile := os.Getenv("atscale_ca_file")
-
-	// default tlsConfig
-	//nolint:gosec // skip verify is currently allowed
-	tlsConfig := &tls.Config{InsecureSkipVerify: true}
-
-	//nolint:nestif // TODO: simplify nested if's
-	if useClientCerts && len(certLocation) > 0 && len(keyLocation) > 0 {
-		// Load client cert if available
-		if cert, loadKeyPairErr := tls.LoadX509KeyPair(certLocation, keyLocation); loadKeyPairErr == nil {
-			if len(caFile) > 0 {
-				caCertPool := x509.NewCertPool()
-				caCert, err := ioutil.ReadFile(caFile)
-				if err != nil {
-					fmt.Printf("Error setting up caFile [%s]:%v\n", caFile, err)
-				}
-				caCertPool.AppendCertsFromPEM(caCert)
-
-				//nolint:gosec // skip verify is currently allowed
-				tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, InsecureSkipVerify: true, RootCAs: caCertPool}
-
-				//nolint:staticcheck // SA1019 TODO: remove this line and let go negotiate the first matching cert
-				tlsConfig.BuildNameToCertificate()
-			} else {
-				//nolint:gosec // skip verify is currently allowed
-				tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, InsecureSkipVerify: true}
-			}
-		}
+	options := TLSOptions{}
+	if useClientCerts {
+		options = TLSOptionsFromEnv()
+	}
+	tlsConfig, err := options.config(false)
+	if err != nil {
+		return &http.Client{Transport: failingTransport{err: fmt.Errorf("error setting up tls: %w", err)}}
 	}
-
 	return &http.Client{
 		Transport: &http.Transport{
-			TLSClientConfig: tlsConfig,
-			Dial:            timeoutDialer(cTimeout, rwTimeout),
+			TLSClientConfig:       tlsConfig,
+			DialContext:           (&net.Dialer{Timeout: cTimeout}).DialContext,
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: rwTimeout,
 		},
 	}
 }
 
+// fails every request, for a client that couldn't be configured
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// the explicit proxy or the one HTTP_PROXY, HTTPS_PROXY and NO_PROXY select
+func (api *API) proxy() (func(*http.Request) (*url.URL, error), error) {
+	if api.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxyUrl, err := url.Parse(api.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	return http.ProxyURL(proxyUrl), nil
+}
+
 func DefaultTimeoutClient() *http.Client {
 	return NewTimeoutClient(connectTimeOut, readWriteTimeout, false)
 }
+
+// idle connections kept per host when API.MaxIdleConnsPerHost is zero, enough for paged queries
+// running in parallel
+const DefaultMaxIdleConnsPerHost = 10
+
+// the client an API and its copies send their requests through, built on first use so connections are
+// pooled across calls
+type pooledClient struct {
+	mu     sync.Mutex
+	client *http.Client
+}
+
+func newPooledClient() *pooledClient {
+	return &pooledClient{}
+}
+
+func (api *API) httpClient() (*http.Client, error) {
+	if api.HTTPClient != nil {
+		return api.HTTPClient, nil
+	}
+	if api.Transport != nil {
+		return &http.Client{Transport: api.Transport}, nil
+	}
+	// APIs not created with NewAPI have nowhere to keep a client, they share one per configuration
+	if api.pooled == nil {
+		return api.sharedHTTPClient(false)
+	}
+	api.pooled.mu.Lock()
+	defer api.pooled.mu.Unlock()
+	if api.pooled.client == nil {
+		client, err := api.newHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		api.pooled.client = client
+	}
+	return api.pooled.client, nil
+}
+
+// the settings newHTTPClient builds a client from
+type clientConfig struct {
+	certFile, keyFile, certPEM, keyPEM string
+	rootCAs                            *x509.CertPool
+	caFile, caPEM                      string
+	minVersion                         uint16
+	serverName                         string
+	insecure                           bool
+	proxyURL                           string
+	connectTimeout, readTimeout        time.Duration
+	maxIdleConnsPerHost                int
+	disableKeepAlives                  bool
+}
+
+func (api *API) tlsOptions() TLSOptions {
+	if api.TLS != nil {
+		return *api.TLS
+	}
+	return TLSOptionsFromEnv()
+}
+
+func (api *API) clientConfig() clientConfig {
+	options := api.tlsOptions()
+	return clientConfig{
+		certFile: options.CertFile, keyFile: options.KeyFile, certPEM: string(options.CertPEM), keyPEM: string(options.KeyPEM),
+		rootCAs: options.RootCAs, caFile: options.CAFile, caPEM: string(options.CAPEM), minVersion: options.MinVersion,
+		serverName: options.ServerName, insecure: api.AllowInsecureTLS, proxyURL: api.ProxyURL,
+		connectTimeout: api.ConnectTimeout, readTimeout: api.ReadTimeout,
+		maxIdleConnsPerHost: api.MaxIdleConnsPerHost, disableKeepAlives: api.DisableKeepAlives,
+	}
+}
+
+// the clients of APIs built as struct literals, keyed by their configuration so their connections are pooled too
+var sharedClients = struct {
+	mu      sync.Mutex
+	clients map[clientConfig]*http.Client
+}{clients: map[clientConfig]*http.Client{}}
+
+// the shared client for the API's configuration, with existing only nil is returned when there is none yet
+func (api *API) sharedHTTPClient(existing bool) (*http.Client, error) {
+	config := api.clientConfig()
+	sharedClients.mu.Lock()
+	defer sharedClients.mu.Unlock()
+	if client, ok := sharedClients.clients[config]; ok || existing {
+		return client, nil
+	}
+	client, err := api.newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	sharedClients.clients[config] = client
+	return client, nil
+}
+
+func (api *API) newHTTPClient() (*http.Client, error) {
+	options := api.tlsOptions()
+	tlsConfig, err := options.config(api.AllowInsecureTLS)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := api.proxy()
+	if err != nil {
+		return nil, err
+	}
+	maxIdleConnsPerHost := api.MaxIdleConnsPerHost
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	dialer := &net.Dialer{Timeout: api.ConnectTimeout, KeepAlive: 30 * time.Second}
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           proxy,
+			DialContext:     dialer.DialContext,
+			// a deadline on the connection would outlive the request it was set for, the read timeout
+			// bounds the wait for the server's answer instead
+			ResponseHeaderTimeout: api.ReadTimeout,
+			TLSHandshakeTimeout:   api.ConnectTimeout,
+			MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+			IdleConnTimeout:       90 * time.Second,
+			DisableKeepAlives:     api.DisableKeepAlives,
+		},
+	}, nil
+}
+
+// CloseIdleConnections closes the pooled connections that aren't in use, e.g. before a long pause
+func (api *API) CloseIdleConnections() {
+	if api.HTTPClient != nil {
+		api.HTTPClient.CloseIdleConnections()
+		return
+	}
+	if api.pooled == nil {
+		if client, _ := api.sharedHTTPClient(true); client != nil {
+			client.CloseIdleConnections()
+		}
+		return
+	}
+	api.pooled.mu.Lock()
+	defer api.pooled.mu.Unlock()
+	if api.pooled.client != nil {
+		api.pooled.client.CloseIdleConnections()
+	}
+}
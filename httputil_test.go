@@ -0,0 +1,61 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// APIs built as struct literals have no pool of their own and share a client per configuration
+func TestStructLiteralAPIsReuseConnections(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprint(w, `<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="0"/><projects/></tsResponse>`)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	for i := 0; i < 5; i++ {
+		api := &API{Server: server.URL, Version: "3.4", ConnectTimeout: time.Second, ReadTimeout: time.Second}
+		if _, err := api.QueryProjects("site"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := atomic.LoadInt32(&connections); got != 1 {
+		t.Fatalf("5 requests opened %d connections, want 1", got)
+	}
+
+	api := &API{ConnectTimeout: time.Second}
+	client, err := api.httpClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := api.httpClient(); again != client {
+		t.Fatal("the same configuration got another client")
+	}
+	other := &API{ConnectTimeout: 2 * time.Second}
+	if otherClient, _ := other.httpClient(); otherClient == client {
+		t.Fatal("another configuration got the same client")
+	}
+}
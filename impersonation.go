@@ -0,0 +1,102 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// user ids by site and lower cased user name. An id goes stale when the user is removed and added again, a
+// sign-in rejecting a cached id evicts it.
+type userIDCache struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+func newUserIDCache() *userIDCache {
+	return &userIDCache{ids: map[string]string{}}
+}
+
+func (c *userIDCache) get(contentUrl, username string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.ids[contentUrl+"/"+strings.ToLower(username)]
+	return id, ok
+}
+
+func (c *userIDCache) put(contentUrl, username, id string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids[contentUrl+"/"+strings.ToLower(username)] = id
+}
+
+func (c *userIDCache) evict(contentUrl, username string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.ids, contentUrl+"/"+strings.ToLower(username))
+}
+
+// signs in as a server administrator impersonating the user named usernameToImpersonate. The first time a
+// user is impersonated this signs in without impersonation to look up the user's id, which is cached afterwards
+// and looked up again when the server rejects it.
+func (api *API) SigninImpersonatingUser(username, password string, contentUrl string, usernameToImpersonate string) error {
+	return api.SigninImpersonatingUserContext(context.Background(), username, password, contentUrl, usernameToImpersonate)
+}
+
+func (api *API) SigninImpersonatingUserContext(ctx context.Context, username, password string, contentUrl string, usernameToImpersonate string) error {
+	userId, cached := api.userIDs.get(contentUrl, usernameToImpersonate)
+	if !cached {
+		var err error
+		if userId, err = api.lookupUserID(ctx, username, password, contentUrl, usernameToImpersonate); err != nil {
+			return err
+		}
+	}
+	err := api.SigninContext(ctx, username, password, contentUrl, userId)
+	if err == nil || !cached || !(errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrNotFound)) {
+		return err
+	}
+	// the user may have been removed and added again under a new id, look it up once more
+	api.userIDs.evict(contentUrl, usernameToImpersonate)
+	if userId, err = api.lookupUserID(ctx, username, password, contentUrl, usernameToImpersonate); err != nil {
+		return err
+	}
+	return api.SigninContext(ctx, username, password, contentUrl, userId)
+}
+
+// signs in without impersonation to look up the id of the user named usernameToImpersonate and caches it
+func (api *API) lookupUserID(ctx context.Context, username, password string, contentUrl string, usernameToImpersonate string) (string, error) {
+	if err := api.SigninContext(ctx, username, password, contentUrl, ""); err != nil {
+		return "", err
+	}
+	user, err := api.GetUserByNameContext(ctx, api.CurrentSiteID(), usernameToImpersonate)
+	if err != nil {
+		return "", err
+	}
+	api.userIDs.put(contentUrl, usernameToImpersonate, user.ID)
+	// don't leave the administrator's session open on the server
+	if err = api.SignoutContext(ctx); err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
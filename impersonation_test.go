@@ -0,0 +1,67 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// a user removed and added again gets a new id, the cached one is looked up again once the sign-in rejects it
+func TestSigninImpersonatingUserStaleID(t *testing.T) {
+	userId := "old-id"
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/auth/signin"):
+			body, _ := ioutil.ReadAll(r.Body)
+			if strings.Contains(string(body), "-id") && !strings.Contains(string(body), userId) {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `<tsResponse><error code="401001"><summary>Signin Error</summary><detail>unknown user</detail></error></tsResponse>`)
+				return
+			}
+			fmt.Fprint(w, `<tsResponse><credentials token="token"><site id="site" contentUrl=""/><user id="admin"/></credentials></tsResponse>`)
+		case strings.HasSuffix(r.URL.Path, "/auth/signout"):
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/users"):
+			lookups++
+			fmt.Fprintf(w, `<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="1"/><users><user id="%s" name="jsmith"/></users></tsResponse>`, userId)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+
+	if err := api.SigninImpersonatingUser("admin", "secret", "", "jsmith"); err != nil {
+		t.Fatal(err)
+	}
+	userId = "new-id"
+	if err := api.SigninImpersonatingUser("admin", "secret", "", "jsmith"); err != nil {
+		t.Fatalf("signing in with a stale cached id returned %v", err)
+	}
+	if err := api.SigninImpersonatingUser("admin", "secret", "", "jsmith"); err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 2 {
+		t.Fatalf("looked the user up %d times, want 2", lookups)
+	}
+	if id, ok := api.userIDs.get("", "jsmith"); !ok || id != "new-id" {
+		t.Fatalf("cached id is %q, want new-id", id)
+	}
+}
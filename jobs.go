@@ -0,0 +1,137 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// background job statuses as reported by QueryJobs
+const JobStatusPending = "Pending"
+const JobStatusInProgress = "InProgress"
+const JobStatusSuccess = "Success"
+const JobStatusFailed = "Failed"
+const JobStatusCancelled = "Cancelled"
+
+const JobTypeRefreshExtracts = "refresh_extracts"
+
+func (api *API) QueryJobs(siteId string, opts ...QueryOption) ([]BackgroundJob, error) {
+	return api.QueryJobsContext(context.Background(), siteId, opts...)
+}
+
+func (api *API) QueryJobsContext(ctx context.Context, siteId string, opts ...QueryOption) ([]BackgroundJob, error) {
+	totalAvailable := 1
+	jobs := []BackgroundJob{}
+	for i := 1; len(jobs) < totalAvailable; i++ {
+		jobsResponse, err := api.QueryJobsByPageContext(ctx, siteId, i, opts...)
+		if err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, jobsResponse.BackgroundJobs.BackgroundJobs...)
+		totalAvailable = jobsResponse.Pagination.TotalAvailable
+	}
+	return jobs, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#query_jobs
+// requires a site or server administrator
+func (api *API) QueryJobsByPage(siteId string, pageNum int, opts ...QueryOption) (QueryJobsResponse, error) {
+	return api.QueryJobsByPageContext(context.Background(), siteId, pageNum, opts...)
+}
+
+func (api *API) QueryJobsByPageContext(ctx context.Context, siteId string, pageNum int, opts ...QueryOption) (QueryJobsResponse, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/jobs", api.Server, api.Version, siteId), pageNum, opts)
+	headers := make(map[string]string)
+	response := QueryJobsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response, err
+}
+
+// a snapshot of the backgrounder load of a site
+type BackgrounderStats struct {
+	TotalJobs int
+	// counts keyed by job type, then by status
+	JobsByType              map[string]map[string]int
+	JobsByStatus            map[string]int
+	PendingExtractRefreshes int
+	// time between a job being queued and a backgrounder picking it up, over the jobs that have started
+	AverageQueueTime time.Duration
+	// how long the oldest job still waiting has been queued
+	LongestPendingTime time.Duration
+}
+
+// summarizes the jobs the server currently knows about by type and status
+func (api *API) GetBackgrounderStats(siteId string) (BackgrounderStats, error) {
+	return api.GetBackgrounderStatsContext(context.Background(), siteId)
+}
+
+func (api *API) GetBackgrounderStatsContext(ctx context.Context, siteId string) (BackgrounderStats, error) {
+	jobs, err := api.QueryJobsContext(ctx, siteId)
+	if err != nil {
+		return BackgrounderStats{}, err
+	}
+	return summarizeJobs(jobs, time.Now()), nil
+}
+
+func summarizeJobs(jobs []BackgroundJob, now time.Time) BackgrounderStats {
+	stats := BackgrounderStats{
+		TotalJobs:    len(jobs),
+		JobsByType:   map[string]map[string]int{},
+		JobsByStatus: map[string]int{},
+	}
+	var queued time.Duration
+	started := 0
+	for _, job := range jobs {
+		if stats.JobsByType[job.JobType] == nil {
+			stats.JobsByType[job.JobType] = map[string]int{}
+		}
+		stats.JobsByType[job.JobType][job.Status]++
+		stats.JobsByStatus[job.Status]++
+		if job.Status == JobStatusPending && job.JobType == JobTypeRefreshExtracts {
+			stats.PendingExtractRefreshes++
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, job.CreatedAt)
+		if err != nil {
+			continue
+		}
+		if job.Status == JobStatusPending {
+			if waiting := now.Sub(createdAt); waiting > stats.LongestPendingTime {
+				stats.LongestPendingTime = waiting
+			}
+			continue
+		}
+		if startedAt, err := time.Parse(time.RFC3339, job.StartedAt); err == nil {
+			queued += startedAt.Sub(createdAt)
+			started++
+		}
+	}
+	if started > 0 {
+		stats.AverageQueueTime = queued / time.Duration(started)
+	}
+	return stats
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#query_job
+func (api *API) QueryJob(siteId, jobId string) (Job, error) {
+	return api.QueryJobContext(context.Background(), siteId, jobId)
+}
+
+func (api *API) QueryJobContext(ctx context.Context, siteId, jobId string) (Job, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/jobs/%s", api.Server, api.Version, siteId, jobId)
+	headers := make(map[string]string)
+	retval := QueryJobResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.Job, err
+}
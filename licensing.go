@@ -0,0 +1,79 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+const TableauCloud = "Tableau Cloud"
+const TableauServer = "Tableau Server"
+
+// seat and version information for license compliance reporting
+type LicenseInfo struct {
+	Deployment     string
+	ProductVersion string
+	Build          string
+	UserQuota      string
+	TotalUsers     int
+	LicensedUsers  int
+	UsersByRole    map[string]int
+}
+
+// Tableau Cloud pods are all served from online.tableau.com
+func (api *API) IsTableauCloud() bool {
+	serverUrl, err := url.Parse(api.Server)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(serverUrl.Hostname()), "online.tableau.com")
+}
+
+// collects the seat usage of the site by role along with the server version. Listing every user requires
+// a site or server administrator.
+func (api *API) GetLicenseInfo(siteId string) (LicenseInfo, error) {
+	return api.GetLicenseInfoContext(context.Background(), siteId)
+}
+
+func (api *API) GetLicenseInfoContext(ctx context.Context, siteId string) (LicenseInfo, error) {
+	info := LicenseInfo{Deployment: TableauServer, UsersByRole: map[string]int{}}
+	if api.IsTableauCloud() {
+		info.Deployment = TableauCloud
+	}
+	serverInfo, err := api.ServerInfoContext(ctx)
+	if err != nil {
+		return info, err
+	}
+	info.ProductVersion = serverInfo.ProductVersion
+	info.Build = serverInfo.Build
+
+	site, err := api.QuerySiteContext(ctx, siteId, false)
+	if err != nil {
+		return info, err
+	}
+	info.UserQuota = site.UserQuota
+
+	users, err := api.QueryUsersOnSiteContext(ctx, siteId)
+	if err != nil {
+		return info, err
+	}
+	for _, user := range users {
+		info.UsersByRole[string(user.SiteRole)]++
+		if user.SiteRole.Licensed() {
+			info.LicensedUsers++
+		}
+	}
+	info.TotalUsers = len(users)
+	return info, nil
+}
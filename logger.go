@@ -0,0 +1,67 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Logger receives the client's log output, set it on API.Logger. Payloads and headers are redacted before
+// they are logged.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// prints every level to stdout, what api.Debug has always done
+type stdoutLogger struct{}
+
+func (stdoutLogger) Debugf(format string, args ...interface{}) { fmt.Printf(format+"\n", args...) }
+func (stdoutLogger) Infof(format string, args ...interface{})  { fmt.Printf(format+"\n", args...) }
+func (stdoutLogger) Errorf(format string, args ...interface{}) { fmt.Printf(format+"\n", args...) }
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(format string, args ...interface{}) {}
+func (nopLogger) Infof(format string, args ...interface{})  {}
+func (nopLogger) Errorf(format string, args ...interface{}) {}
+
+func (api *API) logger() Logger {
+	if api.Logger != nil {
+		return api.Logger
+	}
+	if api.Debug {
+		return stdoutLogger{}
+	}
+	return nopLogger{}
+}
+
+const redacted = "[REDACTED]"
+
+// password="..." and token="..." attributes in xml, "password":"..." and "token":"..." in json, which also
+// covers personalAccessTokenSecret and jwt
+var secretValues = regexp.MustCompile(`(?i)((?:password|token|secret|jwt)"?\s*[=:]\s*")[^"]*`)
+
+// masks the secrets of a request or response body before it's logged
+func redact(payload string) string {
+	return secretValues.ReplaceAllString(payload, "${1}"+redacted)
+}
+
+// a body that is only copied and redacted when a logger formats it, so nothing is spent on large payloads
+// while debug output is off
+type redactedBody []byte
+
+func (body redactedBody) String() string {
+	return redact(string(body))
+}
@@ -0,0 +1,231 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives an observation for every http request the client sends, set it on API.Metrics.
+// endpoint is the request path without the API version and with ids replaced by {id}, e.g.
+// /sites/{id}/datasources, status is 0 when no response was received.
+type MetricsCollector interface {
+	ObserveRequest(endpoint, method string, status int, duration time.Duration, err error)
+	ObserveRetry(endpoint, method string)
+}
+
+var apiPathPrefix = regexp.MustCompile(`^/api/[^/]+`)
+var idPathSegment = regexp.MustCompile(`^([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{32}|\d+)$`)
+
+// keeps the label cardinality bounded, the ids of sites, users and content would create a series each
+func endpointLabel(requestUrl string) string {
+	parsed, err := url.Parse(requestUrl)
+	if err != nil {
+		return "unknown"
+	}
+	segments := strings.Split(apiPathPrefix.ReplaceAllString(parsed.Path, ""), "/")
+	// upload session ids aren't guids, and sites looked up by key are named by their name or content url
+	byKey := parsed.Query().Get("key") != ""
+	for i, segment := range segments {
+		previous := ""
+		if i > 0 {
+			previous = segments[i-1]
+		}
+		if idPathSegment.MatchString(segment) || (segment != "" && (previous == "fileUploads" || (previous == "sites" && byKey))) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.TrimSuffix(strings.Join(segments, "/"), "/")
+}
+
+func (api *API) observeRequest(requestUrl, method string, status int, duration time.Duration, err error) {
+	if api.Metrics != nil {
+		api.Metrics.ObserveRequest(endpointLabel(requestUrl), method, status, duration, err)
+	}
+}
+
+func (api *API) observeRetry(requestUrl, method string) {
+	if api.Metrics != nil {
+		api.Metrics.ObserveRetry(endpointLabel(requestUrl), method)
+	}
+}
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the request duration histogram
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+type requestSeries struct {
+	endpoint string
+	method   string
+	status   string
+}
+
+type latencySeries struct {
+	endpoint string
+	method   string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// PrometheusMetrics is a MetricsCollector that serves its counters in the Prometheus text format, e.g.
+//
+//	metrics := tableau4go.NewPrometheusMetrics()
+//	api.Metrics = metrics
+//	http.Handle("/metrics", metrics)
+type PrometheusMetrics struct {
+	buckets   []float64
+	mu        sync.Mutex
+	requests  map[requestSeries]uint64
+	errors    map[requestSeries]uint64
+	retries   map[latencySeries]uint64
+	latencies map[latencySeries]*histogram
+}
+
+// NewPrometheusMetrics creates a collector with the given latency buckets, DefaultLatencyBuckets when none are given
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &PrometheusMetrics{
+		buckets:   buckets,
+		requests:  map[requestSeries]uint64{},
+		errors:    map[requestSeries]uint64{},
+		retries:   map[latencySeries]uint64{},
+		latencies: map[latencySeries]*histogram{},
+	}
+}
+
+// ObserveRequest counts the request and its duration, transport failures and statuses of 400 and up count as errors
+func (m *PrometheusMetrics) ObserveRequest(endpoint, method string, status int, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	series := requestSeries{endpoint: endpoint, method: method, status: strconv.Itoa(status)}
+	m.requests[series]++
+	if err != nil || status >= http.StatusBadRequest {
+		m.errors[series]++
+	}
+	latency := latencySeries{endpoint: endpoint, method: method}
+	h, ok := m.latencies[latency]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[latency] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// ObserveRetry counts a request the RetryPolicy sends again
+func (m *PrometheusMetrics) ObserveRetry(endpoint, method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries[latencySeries{endpoint: endpoint, method: method}]++
+}
+
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentTypeHeader, "text/plain; version=0.0.4; charset=utf-8")
+	if err := m.Write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Write writes the metrics in the Prometheus text exposition format
+func (m *PrometheusMetrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+	writeCounters(&b, "tableau4go_requests_total", "Requests sent to the Tableau REST API.", m.requests)
+	writeCounters(&b, "tableau4go_request_errors_total", "Requests that failed or were answered with an error status.", m.errors)
+	b.WriteString("# HELP tableau4go_retries_total Requests sent again by the retry policy.\n# TYPE tableau4go_retries_total counter\n")
+	retries := make([]latencySeries, 0, len(m.retries))
+	for series := range m.retries {
+		retries = append(retries, series)
+	}
+	for _, series := range sortLatencySeries(retries) {
+		fmt.Fprintf(&b, "tableau4go_retries_total{%s} %d\n", series.labels(), m.retries[series])
+	}
+	b.WriteString("# HELP tableau4go_request_duration_seconds Duration of the requests to the Tableau REST API.\n# TYPE tableau4go_request_duration_seconds histogram\n")
+	latencies := make([]latencySeries, 0, len(m.latencies))
+	for series := range m.latencies {
+		latencies = append(latencies, series)
+	}
+	for _, series := range sortLatencySeries(latencies) {
+		h := m.latencies[series]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&b, "tableau4go_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", series.labels(), strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(&b, "tableau4go_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", series.labels(), h.count)
+		fmt.Fprintf(&b, "tableau4go_request_duration_seconds_sum{%s} %s\n", series.labels(), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "tableau4go_request_duration_seconds_count{%s} %d\n", series.labels(), h.count)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCounters(b *strings.Builder, name, help string, counters map[requestSeries]uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	series := make([]requestSeries, 0, len(counters))
+	for s := range counters {
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].endpoint != series[j].endpoint {
+			return series[i].endpoint < series[j].endpoint
+		}
+		if series[i].method != series[j].method {
+			return series[i].method < series[j].method
+		}
+		return series[i].status < series[j].status
+	})
+	for _, s := range series {
+		fmt.Fprintf(b, "%s{endpoint=\"%s\",method=\"%s\",status=\"%s\"} %d\n", name, escapeLabel(s.endpoint), escapeLabel(s.method), s.status, counters[s])
+	}
+}
+
+func sortLatencySeries(series []latencySeries) []latencySeries {
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].endpoint != series[j].endpoint {
+			return series[i].endpoint < series[j].endpoint
+		}
+		return series[i].method < series[j].method
+	})
+	return series
+}
+
+func (s latencySeries) labels() string {
+	return fmt.Sprintf("endpoint=\"%s\",method=\"%s\"", escapeLabel(s.endpoint), escapeLabel(s.method))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
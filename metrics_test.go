@@ -0,0 +1,37 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import "testing"
+
+func TestEndpointLabel(t *testing.T) {
+	tests := []struct {
+		requestUrl string
+		want       string
+	}{
+		{"https://tableau/api/3.4/sites/9a8b7c6d-1234-4321-abcd-0123456789ab/datasources", "/sites/{id}/datasources"},
+		{"https://tableau/api/3.4/sites/9a8b7c6d-1234-4321-abcd-0123456789ab/datasources?pageNumber=2", "/sites/{id}/datasources"},
+		{"https://tableau/api/3.4/sites/9a8b7c6d-1234-4321-abcd-0123456789ab/fileUploads/", "/sites/{id}/fileUploads"},
+		{"https://tableau/api/3.4/sites/9a8b7c6d-1234-4321-abcd-0123456789ab/fileUploads/9198:2C4AB3D5E6F7A8B9-1:0", "/sites/{id}/fileUploads/{id}"},
+		{"https://tableau/api/3.4/sites/finance?key=contentUrl", "/sites/{id}"},
+		{"https://tableau/api/3.4/sites/Finance%20EMEA?key=name", "/sites/{id}"},
+		{"https://tableau/api/3.4/sites", "/sites"},
+		{"https://tableau/api/3.4/auth/signin", "/auth/signin"},
+		{"https://tableau/api/3.4/serverinfo", "/serverinfo"},
+		{"https://tableau/api/3.4/sites/9a8b7c6d-1234-4321-abcd-0123456789ab/jobs/12345", "/sites/{id}/jobs/{id}"},
+	}
+	for _, test := range tests {
+		if label := endpointLabel(test.requestUrl); label != test.want {
+			t.Errorf("endpointLabel(%q) = %q, want %q", test.requestUrl, label, test.want)
+		}
+	}
+}
@@ -12,8 +12,10 @@
 package tableau4go
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 )
@@ -22,15 +24,102 @@ const ApiVersion = "2.0"
 const BoundaryString = "813e3160-3c95-11e5-a151-feff819cdc9f"
 
 type API struct {
-	Server              string
-	Version             string
-	Boundary            string
+	Server   string
+	Version  string
+	Boundary string
+	// Deprecated: the token is kept in a session shared by copies of the API, use Token and SetToken. Signing in
+	// still mirrors the token here for the copy it was made through.
 	AuthToken           string
 	OmitDefaultSiteName bool
 	DefaultSiteName     string
-	ConnectTimeout      time.Duration
-	ReadTimeout         time.Duration
-	Debug               bool
+	// bounds dialing and the TLS handshake
+	ConnectTimeout time.Duration
+	// bounds the wait for the server's answer, not the time it takes to read a response, see Timeouts
+	ReadTimeout time.Duration
+	// bound whole calls, with longer limits for publishing and downloading
+	Timeouts RequestTimeouts
+	// prints the log output to stdout when Logger is nil
+	Debug bool
+	// receives the log output, including the requests and responses with their secrets redacted
+	Logger Logger
+	// receives the count, status and latency of every request, see PrometheusMetrics
+	Metrics MetricsCollector
+	// identifies the application in the server's logs, DefaultUserAgent when empty
+	UserAgent string
+	// added to every request, e.g. tenant or tracking headers, the headers a call sets itself take precedence
+	DefaultHeaders map[string]string
+	// gzip request payloads of 8KB and more, for servers behind a proxy that accepts compressed requests.
+	// Responses are always requested gzipped and decompressed transparently.
+	GzipRequests bool
+	// attach a correlation id to every call that has none, see WithRequestID. The id is sent in
+	// RequestIDHeader, DefaultRequestIDHeader when empty.
+	GenerateRequestIDs bool
+	RequestIDHeader    string
+	// check every decoded response against its model and report unknown or missing elements,
+	// meant for tests and debugging as it decodes each response twice
+	ValidateResponses bool
+	// receives the issues found by ValidateResponses, they are logged when nil
+	OnSchemaIssues func(requestUrl string, issues []SchemaIssue)
+	// fail XML responses that aren't tsResponse documents or lack elements of their model with a *SchemaError,
+	// rather than returning zero valued results
+	StrictResponses bool
+	// makes DeleteGroup look the group up first and fail with ErrDeleteAllUsersGroup rather than delete All Users
+	ProtectAllUsersGroup bool
+	// bounds for the archives downloaded from the server, DefaultZipLimits applies when left zero
+	ZipLimits ZipLimits
+	// encodes requests and decodes responses, XMLCodec when nil
+	Codec Codec
+	// called to sign in again when the server reports the session expired, e.g. to mint a fresh JWT.
+	// When nil the credentials of the last successful Signin are replayed.
+	Reauthenticate func(api *API) error
+	// consulted by SigninWithCredentialProvider and on re-authentication, before the credentials of the last Signin
+	CredentialProvider CredentialProvider
+	// the server's session timeouts, used by TokenValid and StartTokenRefresher. The idle timeout defaults to
+	// DefaultSessionIdleTimeout, a zero max lifetime means sessions only expire when idle.
+	SessionIdleTimeout time.Duration
+	SessionMaxLifetime time.Duration
+	// retry requests that fail with 404 or 405 once against the REST API version the server advertises,
+	// for fleets where some servers are older than Version
+	VersionFallback bool
+	// bounds for the version Negotiate picks, e.g. the oldest version whose endpoints the code relies on and
+	// the newest it was tested against
+	MinVersion string
+	MaxVersion string
+	// retries requests failing with 429, 502, 503, 504 or a transient network error, the zero value disables
+	// retries, see DefaultRetryPolicy
+	RetryPolicy RetryPolicy
+	// fails requests fast while the server is down, shared by the API copies and clients it is set on.
+	// See NewCircuitBreaker, nil disables it.
+	CircuitBreaker *CircuitBreaker
+	// caches GET responses, which are revalidated with their ETag or Last-Modified date once older than
+	// CacheTTL. A zero TTL revalidates every time. See NewMemoryCache, nil disables caching.
+	Cache    Cache
+	CacheTTL time.Duration
+	// sends every request instead of the client built from the timeouts and the settings below, for
+	// custom TLS stacks or instrumented transports
+	HTTPClient *http.Client
+	// used when HTTPClient is nil, ConnectTimeout and ReadTimeout don't apply to it
+	Transport http.RoundTripper
+	// skips verifying the server's certificate, only for test servers with self-signed certificates.
+	// Doesn't apply to HTTPClient and Transport.
+	AllowInsecureTLS bool
+	// client certificate, CAs and TLS version for the connections to the server, read from the variables
+	// described by TLSOptionsFromEnv when nil. Doesn't apply to HTTPClient and Transport.
+	TLS *TLSOptions
+	// e.g. http://proxy.example.com:3128, requests go through the proxy HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// select when empty. Doesn't apply to HTTPClient and Transport.
+	ProxyURL string
+	// connection pooling of the client built from the timeouts and the settings above, which are read on the first request.
+	// DefaultMaxIdleConnsPerHost applies when zero.
+	MaxIdleConnsPerHost int
+	DisableKeepAlives   bool
+
+	session *session
+	pooled  *pooledClient
+	userIDs *userIDCache
+	stats   *clientStats
+
+	serverVersion *serverVersionCache
 }
 
 func NewAPI(server string, version string, boundary string, defaultSiteName string, omitDefaultSiteName bool, cTimeout, rTimeout time.Duration) API {
@@ -46,13 +135,46 @@ func NewAPI(server string, version string, boundary string, defaultSiteName stri
 		OmitDefaultSiteName: omitDefaultSiteName,
 		ConnectTimeout:      cTimeout,
 		ReadTimeout:         rTimeout,
+		stats:               newClientStats(),
+		session:             newSession(),
+		pooled:              newPooledClient(),
+		userIDs:             newUserIDCache(),
+		serverVersion:       &serverVersionCache{},
 	}
 }
 
 type Project struct {
-	ID          string `json:"id,omitempty" xml:"id,attr,omitempty"`
-	Name        string `json:"name,omitempty" xml:"name,attr,omitempty"`
-	Description string `json:"description,omitempty" xml:"description,attr,omitempty"`
+	ID                 string             `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name               string             `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Description        string             `json:"description,omitempty" xml:"description,attr,omitempty"`
+	ContentPermissions ContentPermissions `json:"contentPermissions,omitempty" xml:"contentPermissions,attr,omitempty"`
+	// empty for top level projects
+	ParentProjectID string `json:"parentProjectId,omitempty" xml:"parentProjectId,attr,omitempty"`
+}
+
+// ContentPermissions is who manages the permissions of the content in a project
+type ContentPermissions string
+
+const (
+	// the owners of the content set its permissions
+	ContentPermissionsManagedByOwner ContentPermissions = "ManagedByOwner"
+	// the content and nested projects get the project's default permissions
+	ContentPermissionsLockedToProject ContentPermissions = "LockedToProject"
+	// the content gets the project's default permissions, nested projects manage their own
+	ContentPermissionsLockedToProjectWithoutNested ContentPermissions = "LockedToProjectWithoutNested"
+)
+
+func (mode ContentPermissions) Valid() bool {
+	switch mode {
+	case ContentPermissionsManagedByOwner, ContentPermissionsLockedToProject, ContentPermissionsLockedToProjectWithoutNested:
+		return true
+	}
+	return false
+}
+
+// Locked reports whether the permissions of the project's content follow its default permissions
+func (mode ContentPermissions) Locked() bool {
+	return mode == ContentPermissionsLockedToProject || mode == ContentPermissionsLockedToProjectWithoutNested
 }
 
 // for sorting by tableau project name
@@ -86,6 +208,27 @@ func (req CreateProjectRequest) XML() ([]byte, error) {
 	return xml.MarshalIndent(tmp, "", "   ")
 }
 
+// the attributes of a project to change, nil fields are left as they are
+type ProjectUpdate struct {
+	Name               *string             `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Description        *string             `json:"description,omitempty" xml:"description,attr,omitempty"`
+	ContentPermissions *ContentPermissions `json:"contentPermissions,omitempty" xml:"contentPermissions,attr,omitempty"`
+	// moves the project under another one, an empty id moves it to the top level
+	ParentProjectID *string `json:"parentProjectId,omitempty" xml:"parentProjectId,attr,omitempty"`
+}
+
+type UpdateProjectRequest struct {
+	Request ProjectUpdate `json:"project,omitempty" xml:"project,omitempty"`
+}
+
+func (req UpdateProjectRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateProjectRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateProjectRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
 func (p Project) XML() ([]byte, error) {
 	return xml.MarshalIndent(p, "", "   ")
 }
@@ -111,11 +254,39 @@ type Datasource struct {
 	Owner                 *User                  `json:"owner,omitempty" xml:"owner,omitempty"`
 }
 
+type DatasourcePublishOptions struct {
+	Overwrite bool
+	// publish the datasource for Tableau Bridge to keep fresh (Tableau Cloud)
+	UseRemoteQueryAgent bool
+}
+
+type WorkbookCreateRequest struct {
+	Request Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type PublishWorkbookResponse struct {
+	Workbook Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type FileUpload struct {
+	UploadSessionID string `json:"uploadSessionId,omitempty" xml:"uploadSessionId,attr,omitempty"`
+	FileSize        int64  `json:"fileSize,omitempty" xml:"fileSize,attr,omitempty"`
+}
+
+type FileUploadResponse struct {
+	FileUpload FileUpload `json:"fileUpload,omitempty" xml:"fileUpload,omitempty"`
+}
+
+type PublishDatasourceResponse struct {
+	Datasource Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+}
+
 type Datasources struct {
 	Datasources []Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
 }
 
 type QueryDatasourcesResponse struct {
+	Pagination  Pagination  `json:"pagination,omitempty" xml:"pagination,omitempty"`
 	Datasources Datasources `json:"datasources,omitempty" xml:"datasources,omitempty"`
 }
 
@@ -139,6 +310,10 @@ func (req SigninRequest) XML() ([]byte, error) {
 	return xml.MarshalIndent(tmp, "", "   ")
 }
 
+type SwitchSiteRequest struct {
+	Request Site `json:"site,omitempty" xml:"site,omitempty"`
+}
+
 type AuthResponse struct {
 	Credentials *Credentials `json:"credentials,omitempty" xml:"credentials,omitempty"`
 }
@@ -150,6 +325,43 @@ type ServerInfoResponse struct {
 type ServerInfo struct {
 	ProductVersion string `json:"productVersion,omitempty" xml:"productVersion,omitempty"`
 	RestApiVersion string `json:"restApiVersion,omitempty" xml:"restApiVersion,omitempty"`
+	Build          string `json:"build,omitempty" xml:"-"`
+}
+
+// the build number is an attribute of the productVersion element, which a single struct tag can't express
+func (s *ServerInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	tmp := struct {
+		ProductVersion struct {
+			Value string `xml:",chardata"`
+			Build string `xml:"build,attr"`
+		} `xml:"productVersion"`
+		RestApiVersion string `xml:"restApiVersion"`
+	}{}
+	if err := d.DecodeElement(&tmp, &start); err != nil {
+		return err
+	}
+	s.ProductVersion = tmp.ProductVersion.Value
+	s.Build = tmp.ProductVersion.Build
+	s.RestApiVersion = tmp.RestApiVersion
+	return nil
+}
+
+// in JSON the productVersion is an object holding the version as value and the build
+func (s *ServerInfo) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		ProductVersion struct {
+			Value string `json:"value"`
+			Build string `json:"build"`
+		} `json:"productVersion"`
+		RestApiVersion string `json:"restApiVersion"`
+	}{}
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	s.ProductVersion = tmp.ProductVersion.Value
+	s.Build = tmp.ProductVersion.Build
+	s.RestApiVersion = tmp.RestApiVersion
+	return nil
 }
 
 type QueryProjectsResponse struct {
@@ -167,15 +379,49 @@ type Credentials struct {
 	Name        string `json:"name,omitempty" xml:"name,attr,omitempty"`
 	Password    string `json:"password,omitempty" xml:"password,attr,omitempty"`
 	Token       string `json:"token,omitempty" xml:"token,attr,omitempty"`
+	JWT         string `json:"jwt,omitempty" xml:"jwt,attr,omitempty"`
 	Site        *Site  `json:"site,omitempty" xml:"site,omitempty"`
 	Impersonate *User  `json:"user,omitempty" xml:"user,omitempty"`
 }
 
 type User struct {
-	ID       string `json:"id,omitempty" xml:"id,attr,omitempty"`
-	Name     string `json:"name,omitempty" xml:"name,attr,omitempty"`
-	SiteRole string `json:"siteRole,omitempty" xml:"siteRole,attr,omitempty"`
-	FullName string `json:"fullName,omitempty" xml:"fullName,attr,omitempty"`
+	ID          string      `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string      `json:"name,omitempty" xml:"name,attr,omitempty"`
+	SiteRole    SiteRole    `json:"siteRole,omitempty" xml:"siteRole,attr,omitempty"`
+	FullName    string      `json:"fullName,omitempty" xml:"fullName,attr,omitempty"`
+	Email       string      `json:"email,omitempty" xml:"email,attr,omitempty"`
+	LastLogin   string      `json:"lastLogin,omitempty" xml:"lastLogin,attr,omitempty"`
+	AuthSetting AuthSetting `json:"authSetting,omitempty" xml:"authSetting,attr,omitempty"`
+	// the SAML or OpenID Connect configuration the user signs in with when the site has several
+	IdpConfigurationID string `json:"idpConfigurationId,omitempty" xml:"idpConfigurationId,attr,omitempty"`
+}
+
+// UserUpdate holds the user attributes to change, fields left empty are unchanged
+type UserUpdate struct {
+	FullName *string `json:"fullName,omitempty" xml:"fullName,attr,omitempty"`
+	Email    *string `json:"email,omitempty" xml:"email,attr,omitempty"`
+	// only for users signing in with the server's local authentication
+	Password           *string     `json:"password,omitempty" xml:"password,attr,omitempty"`
+	SiteRole           SiteRole    `json:"siteRole,omitempty" xml:"siteRole,attr,omitempty"`
+	AuthSetting        AuthSetting `json:"authSetting,omitempty" xml:"authSetting,attr,omitempty"`
+	IdpConfigurationID string      `json:"idpConfigurationId,omitempty" xml:"idpConfigurationId,attr,omitempty"`
+}
+
+type UpdateUserRequest struct {
+	Request UserUpdate `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+type UserResponse struct {
+	User User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+type Users struct {
+	Users []User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+type QueryUsersResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Users      Users      `json:"users,omitempty" xml:"users,omitempty"`
 }
 
 type QuerySitesResponse struct {
@@ -191,7 +437,7 @@ func (req QuerySitesResponse) XML() ([]byte, error) {
 }
 
 type Sites struct {
-	Sites []Site `json:"sites" xml:"sites,attr"`
+	Sites []Site `json:"site,omitempty" xml:"site,omitempty"`
 }
 
 type QuerySiteResponse struct {
@@ -228,6 +474,105 @@ type Site struct {
 	State        string     `json:"state,omitempty" xml:"state,attr,omitempty"`
 	StatusReason string     `json:"statusReason,omitempty" xml:"statusReason,attr,omitempty"`
 	Usage        *SiteUsage `json:"usage,omitempty" xml:"usage,omitempty"`
+
+	RevisionHistoryEnabled bool   `json:"revisionHistoryEnabled,omitempty" xml:"revisionHistoryEnabled,attr,omitempty"`
+	RevisionLimit          int    `json:"revisionLimit,omitempty" xml:"revisionLimit,attr,omitempty"`
+	WebhooksEnabled        bool   `json:"webhooksEnabled,omitempty" xml:"webhooksEnabled,attr,omitempty"`
+	ExtractEncryptionMode  string `json:"extractEncryptionMode,omitempty" xml:"extractEncryptionMode,attr,omitempty"`
+	RequestAccessEnabled   bool   `json:"requestAccessEnabled,omitempty" xml:"requestAccessEnabled,attr,omitempty"`
+
+	// subscriptions, alerts and commenting
+	DisableSubscriptions      bool `json:"disableSubscriptions,omitempty" xml:"disableSubscriptions,attr,omitempty"`
+	SubscribeOthersEnabled    bool `json:"subscribeOthersEnabled,omitempty" xml:"subscribeOthersEnabled,attr,omitempty"`
+	DataAlertsEnabled         bool `json:"dataAlertsEnabled,omitempty" xml:"dataAlertsEnabled,attr,omitempty"`
+	CommentingEnabled         bool `json:"commentingEnabled,omitempty" xml:"commentingEnabled,attr,omitempty"`
+	CommentingMentionsEnabled bool `json:"commentingMentionsEnabled,omitempty" xml:"commentingMentionsEnabled,attr,omitempty"`
+}
+
+// SiteUpdate holds the site attributes to change, only the fields that are set are sent to the server
+type SiteUpdate struct {
+	Name       *string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ContentUrl *string `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	// SiteAdminModeContentAndUsers or SiteAdminModeContentOnly
+	AdminMode *string `json:"adminMode,omitempty" xml:"adminMode,attr,omitempty"`
+	// SiteStateActive or SiteStateSuspended
+	State        *string `json:"state,omitempty" xml:"state,attr,omitempty"`
+	UserQuota    *int    `json:"userQuota,omitempty" xml:"userQuota,attr,omitempty"`
+	StorageQuota *int    `json:"storageQuota,omitempty" xml:"storageQuota,attr,omitempty"`
+
+	RevisionHistoryEnabled *bool   `json:"revisionHistoryEnabled,omitempty" xml:"revisionHistoryEnabled,attr,omitempty"`
+	RevisionLimit          *int    `json:"revisionLimit,omitempty" xml:"revisionLimit,attr,omitempty"`
+	WebhooksEnabled        *bool   `json:"webhooksEnabled,omitempty" xml:"webhooksEnabled,attr,omitempty"`
+	ExtractEncryptionMode  *string `json:"extractEncryptionMode,omitempty" xml:"extractEncryptionMode,attr,omitempty"`
+	RequestAccessEnabled   *bool   `json:"requestAccessEnabled,omitempty" xml:"requestAccessEnabled,attr,omitempty"`
+
+	DisableSubscriptions      *bool `json:"disableSubscriptions,omitempty" xml:"disableSubscriptions,attr,omitempty"`
+	SubscribeOthersEnabled    *bool `json:"subscribeOthersEnabled,omitempty" xml:"subscribeOthersEnabled,attr,omitempty"`
+	DataAlertsEnabled         *bool `json:"dataAlertsEnabled,omitempty" xml:"dataAlertsEnabled,attr,omitempty"`
+	CommentingEnabled         *bool `json:"commentingEnabled,omitempty" xml:"commentingEnabled,attr,omitempty"`
+	CommentingMentionsEnabled *bool `json:"commentingMentionsEnabled,omitempty" xml:"commentingMentionsEnabled,attr,omitempty"`
+}
+
+// SiteRequest describes a site to create, the server's defaults apply to the fields left unset
+type SiteRequest struct {
+	Name       string `json:"name" xml:"name,attr"`
+	ContentUrl string `json:"contentUrl" xml:"contentUrl,attr"`
+	// SiteAdminModeContentAndUsers or SiteAdminModeContentOnly
+	AdminMode string `json:"adminMode,omitempty" xml:"adminMode,attr,omitempty"`
+	// the maximum number of users, can't be combined with the tier capacities of user based licensing
+	UserQuota *int `json:"userQuota,omitempty" xml:"userQuota,attr,omitempty"`
+	// in megabytes
+	StorageQuota *int `json:"storageQuota,omitempty" xml:"storageQuota,attr,omitempty"`
+
+	RevisionHistoryEnabled *bool   `json:"revisionHistoryEnabled,omitempty" xml:"revisionHistoryEnabled,attr,omitempty"`
+	RevisionLimit          *int    `json:"revisionLimit,omitempty" xml:"revisionLimit,attr,omitempty"`
+	WebhooksEnabled        *bool   `json:"webhooksEnabled,omitempty" xml:"webhooksEnabled,attr,omitempty"`
+	ExtractEncryptionMode  *string `json:"extractEncryptionMode,omitempty" xml:"extractEncryptionMode,attr,omitempty"`
+	RequestAccessEnabled   *bool   `json:"requestAccessEnabled,omitempty" xml:"requestAccessEnabled,attr,omitempty"`
+
+	DisableSubscriptions      *bool `json:"disableSubscriptions,omitempty" xml:"disableSubscriptions,attr,omitempty"`
+	SubscribeOthersEnabled    *bool `json:"subscribeOthersEnabled,omitempty" xml:"subscribeOthersEnabled,attr,omitempty"`
+	DataAlertsEnabled         *bool `json:"dataAlertsEnabled,omitempty" xml:"dataAlertsEnabled,attr,omitempty"`
+	CommentingEnabled         *bool `json:"commentingEnabled,omitempty" xml:"commentingEnabled,attr,omitempty"`
+	CommentingMentionsEnabled *bool `json:"commentingMentionsEnabled,omitempty" xml:"commentingMentionsEnabled,attr,omitempty"`
+}
+
+const SiteAdminModeContentAndUsers = "ContentAndUsers"
+const SiteAdminModeContentOnly = "ContentOnly"
+
+// SiteState is whether users can sign in to a site, see SetSiteState
+type SiteState string
+
+const SiteStateActive SiteState = "Active"
+const SiteStateSuspended SiteState = "Suspended"
+
+type CreateSiteRequest struct {
+	Request SiteRequest `json:"site,omitempty" xml:"site,omitempty"`
+}
+
+type UpdateSiteRequest struct {
+	Request SiteUpdate `json:"site,omitempty" xml:"site,omitempty"`
+}
+
+func (req UpdateSiteRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateSiteRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateSiteRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+// helpers to fill in the optional fields of the update structs
+func Bool(b bool) *bool {
+	return &b
+}
+
+func Int(i int) *int {
+	return &i
+}
+
+func String(s string) *string {
+	return &s
 }
 
 type SiteUsage struct {
@@ -269,3 +614,542 @@ type StatusError struct {
 func (e *StatusError) Error() string {
 	return fmt.Sprintf("%d - %s.  Request URL was: %s", e.Code, e.Msg, e.URL)
 }
+
+type Workbook struct {
+	ID          string   `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string   `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Description string   `json:"description,omitempty" xml:"description,attr,omitempty"`
+	ContentUrl  string   `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	WebpageUrl  string   `json:"webpageUrl,omitempty" xml:"webpageUrl,attr,omitempty"`
+	ShowTabs    bool     `json:"showTabs,omitempty" xml:"showTabs,attr,omitempty"`
+	Size        int64    `json:"size,omitempty" xml:"size,attr,omitempty"`
+	CreatedAt   string   `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt   string   `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Project     *Project `json:"project,omitempty" xml:"project,omitempty"`
+	Owner       *User    `json:"owner,omitempty" xml:"owner,omitempty"`
+	// only returned by GetWorkbook
+	Tags          *Tags  `json:"tags,omitempty" xml:"tags,omitempty"`
+	Views         *Views `json:"views,omitempty" xml:"views,omitempty"`
+	DefaultViewID string `json:"defaultViewId,omitempty" xml:"defaultViewId,attr,omitempty"`
+}
+
+// the labels of the workbook's tags
+func (w Workbook) TagLabels() []string {
+	labels := []string{}
+	if w.Tags != nil {
+		for _, tag := range w.Tags.Tags {
+			labels = append(labels, tag.Label)
+		}
+	}
+	return labels
+}
+
+type Tag struct {
+	Label string `json:"label,omitempty" xml:"label,attr,omitempty"`
+}
+
+type Tags struct {
+	Tags []Tag `json:"tag,omitempty" xml:"tag,omitempty"`
+}
+
+// the attributes of a workbook to change, nil fields are left as they are
+type WorkbookUpdate struct {
+	Name     *string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ShowTabs *bool   `json:"showTabs,omitempty" xml:"showTabs,attr,omitempty"`
+	// moves the workbook, only the ID is sent
+	Project *Project `json:"project,omitempty" xml:"project,omitempty"`
+	// transfers the ownership, only the ID is sent
+	Owner *User `json:"owner,omitempty" xml:"owner,omitempty"`
+}
+
+type UpdateWorkbookRequest struct {
+	Request WorkbookUpdate `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type WorkbookResponse struct {
+	Workbook Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type Workbooks struct {
+	Workbooks []Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type QueryWorkbooksResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Workbooks  Workbooks  `json:"workbooks,omitempty" xml:"workbooks,omitempty"`
+}
+
+type View struct {
+	ID          string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string     `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ContentUrl  string     `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	ViewUrlName string     `json:"viewUrlName,omitempty" xml:"viewUrlName,attr,omitempty"`
+	CreatedAt   string     `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt   string     `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Workbook    *Workbook  `json:"workbook,omitempty" xml:"workbook,omitempty"`
+	Owner       *User      `json:"owner,omitempty" xml:"owner,omitempty"`
+	Project     *Project   `json:"project,omitempty" xml:"project,omitempty"`
+	Usage       *ViewUsage `json:"usage,omitempty" xml:"usage,omitempty"`
+}
+
+// only returned when views are queried with their usage
+type ViewUsage struct {
+	TotalViewCount int `json:"totalViewCount,omitempty" xml:"totalViewCount,attr,omitempty"`
+}
+
+type Views struct {
+	Views []View `json:"view,omitempty" xml:"view,omitempty"`
+}
+
+type QueryViewsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Views      Views      `json:"views,omitempty" xml:"views,omitempty"`
+}
+
+type ViewResponse struct {
+	View View `json:"view,omitempty" xml:"view,omitempty"`
+}
+
+// Recent is an item the signed in user viewed lately, either a workbook or a view
+type Recent struct {
+	Workbook *Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+	View     *View     `json:"view,omitempty" xml:"view,omitempty"`
+}
+
+type Recents struct {
+	Recents []Recent `json:"recent,omitempty" xml:"recent,omitempty"`
+}
+
+type RecentlyViewedResponse struct {
+	Recents Recents `json:"recents,omitempty" xml:"recents,omitempty"`
+}
+
+type Revision struct {
+	RevisionNumber int    `json:"revisionNumber,omitempty" xml:"revisionNumber,attr,omitempty"`
+	PublishedAt    string `json:"publishedAt,omitempty" xml:"publishedAt,attr,omitempty"`
+	Deleted        bool   `json:"deleted,omitempty" xml:"deleted,attr,omitempty"`
+	Current        bool   `json:"current,omitempty" xml:"current,attr,omitempty"`
+	SizeInBytes    int64  `json:"sizeInBytes,omitempty" xml:"sizeInBytes,attr,omitempty"`
+	Publisher      *User  `json:"publisher,omitempty" xml:"publisher,omitempty"`
+}
+
+type Revisions struct {
+	Revisions []Revision `json:"revision,omitempty" xml:"revision,omitempty"`
+}
+
+type QueryRevisionsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Revisions  Revisions  `json:"revisions,omitempty" xml:"revisions,omitempty"`
+}
+
+type VirtualConnection struct {
+	ID          string   `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string   `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Description string   `json:"description,omitempty" xml:"description,attr,omitempty"`
+	CreatedAt   string   `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt   string   `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Project     *Project `json:"project,omitempty" xml:"project,omitempty"`
+	Owner       *User    `json:"owner,omitempty" xml:"owner,omitempty"`
+}
+
+type VirtualConnections struct {
+	VirtualConnections []VirtualConnection `json:"virtualConnection,omitempty" xml:"virtualConnection,omitempty"`
+}
+
+type QueryVirtualConnectionsResponse struct {
+	Pagination         Pagination         `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	VirtualConnections VirtualConnections `json:"virtualConnections,omitempty" xml:"virtualConnections,omitempty"`
+}
+
+// a database connection of a workbook, datasource or virtual connection
+type Connection struct {
+	ID            string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Type          string `json:"type,omitempty" xml:"type,attr,omitempty"`
+	ServerAddress string `json:"serverAddress,omitempty" xml:"serverAddress,attr,omitempty"`
+	ServerPort    string `json:"serverPort,omitempty" xml:"serverPort,attr,omitempty"`
+	UserName      string `json:"userName,omitempty" xml:"userName,attr,omitempty"`
+	// whether the password is stored with the connection rather than asked for
+	EmbedPassword bool `json:"embedPassword,omitempty" xml:"embedPassword,attr,omitempty"`
+	// the published or embedded datasource the connection belongs to, returned for workbook connections
+	Datasource *Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+}
+
+type Connections struct {
+	Connections []Connection `json:"connection,omitempty" xml:"connection,omitempty"`
+}
+
+type QueryConnectionsResponse struct {
+	Pagination  Pagination  `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Connections Connections `json:"connections,omitempty" xml:"connections,omitempty"`
+}
+
+type UpdateConnectionResponse struct {
+	Connection Connection `json:"connection,omitempty" xml:"connection,omitempty"`
+}
+
+// ConnectionUpdate holds the connection attributes to change, only the fields that are set are sent to the server
+type ConnectionUpdate struct {
+	ServerAddress *string `json:"serverAddress,omitempty" xml:"serverAddress,attr,omitempty"`
+	ServerPort    *string `json:"serverPort,omitempty" xml:"serverPort,attr,omitempty"`
+	UserName      *string `json:"userName,omitempty" xml:"userName,attr,omitempty"`
+	Password      *string `json:"password,omitempty" xml:"password,attr,omitempty"`
+	// store Password with the connection, only for workbook and datasource connections
+	EmbedPassword *bool `json:"embedPassword,omitempty" xml:"embedPassword,attr,omitempty"`
+}
+
+type UpdateConnectionRequest struct {
+	Request ConnectionUpdate `json:"connection,omitempty" xml:"connection,omitempty"`
+}
+
+func (req UpdateConnectionRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateConnectionRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateConnectionRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type Session struct {
+	ID   string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Site *Site  `json:"site,omitempty" xml:"site,omitempty"`
+	User *User  `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+type Sessions struct {
+	Sessions []Session `json:"session,omitempty" xml:"session,omitempty"`
+}
+
+type QuerySessionResponse struct {
+	Session Session `json:"session,omitempty" xml:"session,omitempty"`
+}
+
+type QuerySessionsResponse struct {
+	Sessions Sessions `json:"sessions,omitempty" xml:"sessions,omitempty"`
+}
+
+type BackgroundJob struct {
+	ID        string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Status    string `json:"status,omitempty" xml:"status,attr,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	StartedAt string `json:"startedAt,omitempty" xml:"startedAt,attr,omitempty"`
+	EndedAt   string `json:"endedAt,omitempty" xml:"endedAt,attr,omitempty"`
+	Priority  int    `json:"priority,omitempty" xml:"priority,attr,omitempty"`
+	JobType   string `json:"jobType,omitempty" xml:"jobType,attr,omitempty"`
+	Title     string `json:"title,omitempty" xml:"title,attr,omitempty"`
+	Subtitle  string `json:"subtitle,omitempty" xml:"subtitle,attr,omitempty"`
+}
+
+type BackgroundJobs struct {
+	BackgroundJobs []BackgroundJob `json:"backgroundJob,omitempty" xml:"backgroundJob,omitempty"`
+}
+
+type QueryJobsResponse struct {
+	Pagination     Pagination     `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	BackgroundJobs BackgroundJobs `json:"backgroundJobs,omitempty" xml:"backgroundJobs,omitempty"`
+}
+
+type Job struct {
+	ID                string             `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Mode              string             `json:"mode,omitempty" xml:"mode,attr,omitempty"`
+	Type              string             `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Progress          int                `json:"progress,omitempty" xml:"progress,attr,omitempty"`
+	CreatedAt         string             `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	StartedAt         string             `json:"startedAt,omitempty" xml:"startedAt,attr,omitempty"`
+	CompletedAt       string             `json:"completedAt,omitempty" xml:"completedAt,attr,omitempty"`
+	FinishCode        int                `json:"finishCode,omitempty" xml:"finishCode,attr,omitempty"`
+	ExtractRefreshJob *ExtractRefreshJob `json:"extractRefreshJob,omitempty" xml:"extractRefreshJob,omitempty"`
+}
+
+type ExtractRefreshJob struct {
+	Notes      string      `json:"notes,omitempty" xml:"notes,attr,omitempty"`
+	Datasource *Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+	Workbook   *Workbook   `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type QueryJobResponse struct {
+	Job Job `json:"job,omitempty" xml:"job,omitempty"`
+}
+
+type BridgeClient struct {
+	ID            string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name          string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Version       string `json:"version,omitempty" xml:"version,attr,omitempty"`
+	Status        string `json:"status,omitempty" xml:"status,attr,omitempty"`
+	LastConnected string `json:"lastConnected,omitempty" xml:"lastConnected,attr,omitempty"`
+	PoolID        string `json:"poolId,omitempty" xml:"poolId,attr,omitempty"`
+	Owner         *User  `json:"owner,omitempty" xml:"owner,omitempty"`
+}
+
+type BridgeClients struct {
+	BridgeClients []BridgeClient `json:"bridgeClient,omitempty" xml:"bridgeClient,omitempty"`
+}
+
+type QueryBridgeClientsResponse struct {
+	Pagination    Pagination    `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	BridgeClients BridgeClients `json:"bridgeClients,omitempty" xml:"bridgeClients,omitempty"`
+}
+
+type BridgePool struct {
+	ID          string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Description string `json:"description,omitempty" xml:"description,attr,omitempty"`
+}
+
+type BridgePools struct {
+	BridgePools []BridgePool `json:"bridgePool,omitempty" xml:"bridgePool,omitempty"`
+}
+
+type QueryBridgePoolsResponse struct {
+	Pagination  Pagination  `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	BridgePools BridgePools `json:"bridgePools,omitempty" xml:"bridgePools,omitempty"`
+}
+
+type CreateBridgePoolRequest struct {
+	Request BridgePool `json:"bridgePool,omitempty" xml:"bridgePool,omitempty"`
+}
+
+func (req CreateBridgePoolRequest) XML() ([]byte, error) {
+	tmp := struct {
+		CreateBridgePoolRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{CreateBridgePoolRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type CreateBridgePoolResponse struct {
+	BridgePool BridgePool `json:"bridgePool,omitempty" xml:"bridgePool,omitempty"`
+}
+
+type QueryBridgeClientResponse struct {
+	BridgeClient BridgeClient `json:"bridgeClient,omitempty" xml:"bridgeClient,omitempty"`
+}
+
+// BridgeClientUpdate holds the client attributes to change, only the fields that are set are sent to the server
+type BridgeClientUpdate struct {
+	Name  *string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Owner *User   `json:"owner,omitempty" xml:"owner,omitempty"`
+}
+
+type UpdateBridgeClientRequest struct {
+	Request BridgeClientUpdate `json:"bridgeClient,omitempty" xml:"bridgeClient,omitempty"`
+}
+
+func (req UpdateBridgeClientRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateBridgeClientRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateBridgeClientRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type AddUserRequest struct {
+	Request User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+func (req AddUserRequest) XML() ([]byte, error) {
+	tmp := struct {
+		AddUserRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{AddUserRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type Group struct {
+	ID     string       `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name   string       `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Domain *Domain      `json:"domain,omitempty" xml:"domain,omitempty"`
+	Import *GroupImport `json:"import,omitempty" xml:"import,omitempty"`
+	// the site role local groups grant their members on sign in, API 3.21 and later
+	MinimumSiteRole SiteRole `json:"minimumSiteRole,omitempty" xml:"minimumSiteRole,attr,omitempty"`
+}
+
+// Imported reports whether the group's members are synchronized from Active Directory
+func (group Group) Imported() bool {
+	return group.Import != nil && group.Import.Source != ""
+}
+
+// GroupImport ties a group to a directory group, members are synchronized from the directory
+type GroupImport struct {
+	// GroupImportSourceActiveDirectory
+	Source     string `json:"source,omitempty" xml:"source,attr,omitempty"`
+	DomainName string `json:"domainName,omitempty" xml:"domainName,attr,omitempty"`
+	// GrantLicenseOnLogin or GrantLicenseOnSync, leave empty to not grant a license to the members
+	GrantLicenseMode string `json:"grantLicenseMode,omitempty" xml:"grantLicenseMode,attr,omitempty"`
+	// the site role given to the members when a license is granted
+	SiteRole SiteRole `json:"siteRole,omitempty" xml:"siteRole,attr,omitempty"`
+}
+
+const GroupImportSourceActiveDirectory = "ActiveDirectory"
+
+const (
+	GrantLicenseOnLogin = "onLogin"
+	GrantLicenseOnSync  = "onSync"
+)
+
+// GroupRequest describes a group to create, set Import to create the group from Active Directory
+type GroupRequest struct {
+	Name string `json:"name" xml:"name,attr"`
+	// the site role given to the members of a local group when they sign in, requires API 3.21
+	MinimumSiteRole SiteRole     `json:"minimumSiteRole,omitempty" xml:"minimumSiteRole,attr,omitempty"`
+	Import          *GroupImport `json:"import,omitempty" xml:"import,omitempty"`
+}
+
+type Domain struct {
+	Name string `json:"name,omitempty" xml:"name,attr,omitempty"`
+}
+
+type Groups struct {
+	Groups []Group `json:"group,omitempty" xml:"group,omitempty"`
+}
+
+type QueryGroupsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Groups     Groups     `json:"groups,omitempty" xml:"groups,omitempty"`
+}
+
+type CreateGroupRequest struct {
+	Request GroupRequest `json:"group,omitempty" xml:"group,omitempty"`
+}
+
+func (req CreateGroupRequest) XML() ([]byte, error) {
+	tmp := struct {
+		CreateGroupRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{CreateGroupRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type UpdateGroupRequest struct {
+	Request GroupRequest `json:"group,omitempty" xml:"group,omitempty"`
+}
+
+func (req UpdateGroupRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateGroupRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateGroupRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type CreateGroupResponse struct {
+	Group Group `json:"group,omitempty" xml:"group,omitempty"`
+}
+
+type SiteAuthConfiguration struct {
+	AuthSetting          string `json:"authSetting,omitempty" xml:"authSetting,attr,omitempty"`
+	KnownProviderAlias   string `json:"knownProviderAlias,omitempty" xml:"knownProviderAlias,attr,omitempty"`
+	IdpConfigurationName string `json:"idpConfigurationName,omitempty" xml:"idpConfigurationName,attr,omitempty"`
+	IdpConfigurationID   string `json:"idpConfigurationId,omitempty" xml:"idpConfigurationId,attr,omitempty"`
+	Enabled              bool   `json:"enabled,omitempty" xml:"enabled,attr,omitempty"`
+}
+
+type SiteAuthConfigurations struct {
+	SiteAuthConfigurations []SiteAuthConfiguration `json:"siteAuthConfiguration,omitempty" xml:"siteAuthConfiguration,omitempty"`
+}
+
+type QuerySiteAuthConfigurationsResponse struct {
+	SiteAuthConfigurations SiteAuthConfigurations `json:"siteAuthConfigurations,omitempty" xml:"siteAuthConfigurations,omitempty"`
+}
+
+type PersonalAccessToken struct {
+	ID         string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name       string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	CreatedAt  string `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	LastUsedAt string `json:"lastUsedAt,omitempty" xml:"lastUsedAt,attr,omitempty"`
+	ExpiresAt  string `json:"expiresAt,omitempty" xml:"expiresAt,attr,omitempty"`
+	Owner      *User  `json:"owner,omitempty" xml:"owner,omitempty"`
+}
+
+type PersonalAccessTokens struct {
+	PersonalAccessTokens []PersonalAccessToken `json:"personalAccessToken,omitempty" xml:"personalAccessToken,omitempty"`
+}
+
+type QueryPersonalAccessTokensResponse struct {
+	Pagination           Pagination           `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	PersonalAccessTokens PersonalAccessTokens `json:"personalAccessTokens,omitempty" xml:"personalAccessTokens,omitempty"`
+}
+
+// a permission rule, e.g. Capability{Name: CapabilityRead, Mode: CapabilityAllow}
+type Capability struct {
+	Name string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Mode string `json:"mode,omitempty" xml:"mode,attr,omitempty"`
+}
+
+const (
+	CapabilityAllow = "Allow"
+	CapabilityDeny  = "Deny"
+)
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_concepts_permissions.htm
+const (
+	CapabilityAddComment         = "AddComment"
+	CapabilityChangeHierarchy    = "ChangeHierarchy"
+	CapabilityChangePermissions  = "ChangePermissions"
+	CapabilityConnect            = "Connect"
+	CapabilityDelete             = "Delete"
+	CapabilityExecute            = "Execute"
+	CapabilityExportData         = "ExportData"
+	CapabilityExportImage        = "ExportImage"
+	CapabilityExportXml          = "ExportXml"
+	CapabilityFilter             = "Filter"
+	CapabilityRead               = "Read"
+	CapabilityShareView          = "ShareView"
+	CapabilityViewComments       = "ViewComments"
+	CapabilityViewUnderlyingData = "ViewUnderlyingData"
+	CapabilityWebAuthoring       = "WebAuthoring"
+	CapabilityWrite              = "Write"
+)
+
+type Capabilities struct {
+	Capabilities []Capability `json:"capability,omitempty" xml:"capability,omitempty"`
+}
+
+// the rules of a single group or user, exactly one of Group and User is set
+type GranteeCapabilities struct {
+	Group        *Group       `json:"group,omitempty" xml:"group,omitempty"`
+	User         *User        `json:"user,omitempty" xml:"user,omitempty"`
+	Capabilities Capabilities `json:"capabilities,omitempty" xml:"capabilities,omitempty"`
+}
+
+// helpers to build the rules given to AddDefaultPermissions
+func GroupCapabilities(groupId string, capabilities ...Capability) GranteeCapabilities {
+	return GranteeCapabilities{Group: &Group{ID: groupId}, Capabilities: Capabilities{Capabilities: capabilities}}
+}
+
+func UserCapabilities(userId string, capabilities ...Capability) GranteeCapabilities {
+	return GranteeCapabilities{User: &User{ID: userId}, Capabilities: Capabilities{Capabilities: capabilities}}
+}
+
+type Permissions struct {
+	Project             *Project              `json:"project,omitempty" xml:"project,omitempty"`
+	GranteeCapabilities []GranteeCapabilities `json:"granteeCapabilities,omitempty" xml:"granteeCapabilities,omitempty"`
+}
+
+type PermissionsResponse struct {
+	Permissions Permissions `json:"permissions,omitempty" xml:"permissions,omitempty"`
+}
+
+type AddPermissionsRequest struct {
+	Request Permissions `json:"permissions,omitempty" xml:"permissions,omitempty"`
+}
+
+func (req AddPermissionsRequest) XML() ([]byte, error) {
+	tmp := struct {
+		AddPermissionsRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{AddPermissionsRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+// the project a workbook, datasource or flow moves to
+type contentMove struct {
+	Project *Project `json:"project,omitempty" xml:"project,omitempty"`
+}
+
+type moveDatasourceRequest struct {
+	Request contentMove `json:"datasource" xml:"datasource"`
+}
+
+type moveFlowRequest struct {
+	Request contentMove `json:"flow" xml:"flow"`
+}
@@ -0,0 +1,81 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContentKind is the type of a piece of content a project holds
+type ContentKind string
+
+const (
+	ContentWorkbook   ContentKind = "workbook"
+	ContentDatasource ContentKind = "datasource"
+	ContentFlow       ContentKind = "flow"
+)
+
+// ContentRef points at a workbook, datasource or flow
+type ContentRef struct {
+	Kind ContentKind
+	ID   string
+}
+
+// the key of the item in the BulkReport of MoveContent, e.g. workbook/1f2e...
+func (ref ContentRef) String() string {
+	return fmt.Sprintf("%s/%s", ref.Kind, ref.ID)
+}
+
+// moves the items to the target project with DefaultBulkConcurrency requests at a time. Every item is attempted,
+// the report has the outcome of each keyed by ContentRef.String and report.Err() is nil when all of them moved.
+func (api *API) MoveContent(siteId string, items []ContentRef, targetProjectId string) BulkReport {
+	return api.MoveContentContext(context.Background(), siteId, items, targetProjectId)
+}
+
+func (api *API) MoveContentContext(ctx context.Context, siteId string, items []ContentRef, targetProjectId string) BulkReport {
+	refs := make(map[string]ContentRef, len(items))
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		refs[item.String()] = item
+		keys = append(keys, item.String())
+	}
+	report := NewBulkExecutor(DefaultBulkConcurrency).Run(ctx, keys, func(ctx context.Context, key string) (interface{}, error) {
+		return nil, api.moveContent(ctx, siteId, refs[key], targetProjectId)
+	})
+	api.loggerFor(ctx).Debugf("Moved %d of %d items to projectId %s on siteId %s", report.Succeeded, len(items), targetProjectId, siteId)
+	return report
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_source
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#update_flow
+func (api *API) moveContent(ctx context.Context, siteId string, ref ContentRef, targetProjectId string) error {
+	move := contentMove{Project: &Project{ID: targetProjectId}}
+	var request interface{}
+	switch ref.Kind {
+	case ContentWorkbook:
+		_, err := api.UpdateWorkbookContext(ctx, siteId, ref.ID, WorkbookUpdate{Project: move.Project})
+		return err
+	case ContentDatasource:
+		request = moveDatasourceRequest{Request: move}
+	case ContentFlow:
+		request = moveFlowRequest{Request: move}
+	default:
+		return fmt.Errorf("unknown content kind '%s'", ref.Kind)
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/%ss/%s", api.Server, api.Version, siteId, ref.Kind, ref.ID)
+	payload, headers, err := api.encodeRequest(request)
+	if err != nil {
+		return err
+	}
+	return api.makeRequest(ctx, requestUrl, PUT, payload, nil, headers)
+}
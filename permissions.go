@@ -0,0 +1,145 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// the kinds of content a project holds default permissions for
+type PermissionResource string
+
+const (
+	PermissionResourceWorkbooks   PermissionResource = "workbooks"
+	PermissionResourceDatasources PermissionResource = "datasources"
+	PermissionResourceFlows       PermissionResource = "flows"
+)
+
+// the rules content published to the project starts with
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#query_default_permissions
+func (api *API) QueryDefaultPermissions(siteId, projectId string, resource PermissionResource) (Permissions, error) {
+	return api.QueryDefaultPermissionsContext(context.Background(), siteId, projectId, resource)
+}
+
+func (api *API) QueryDefaultPermissionsContext(ctx context.Context, siteId, projectId string, resource PermissionResource) (Permissions, error) {
+	requestUrl := api.defaultPermissionsUrl(siteId, projectId, resource)
+	headers := make(map[string]string)
+	retval := PermissionsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.Permissions, err
+}
+
+// adds rules to the project's default permissions, existing rules of other grantees and capabilities are kept.
+// e.g. AddDefaultPermissions(siteId, projectId, PermissionResourceWorkbooks, GroupCapabilities(groupId, Capability{Name: CapabilityRead, Mode: CapabilityAllow}))
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#add_default_permissions
+func (api *API) AddDefaultPermissions(siteId, projectId string, resource PermissionResource, grants ...GranteeCapabilities) (Permissions, error) {
+	return api.AddDefaultPermissionsContext(context.Background(), siteId, projectId, resource, grants...)
+}
+
+func (api *API) AddDefaultPermissionsContext(ctx context.Context, siteId, projectId string, resource PermissionResource, grants ...GranteeCapabilities) (Permissions, error) {
+	requestUrl := api.defaultPermissionsUrl(siteId, projectId, resource)
+	payload, headers, err := api.encodeRequest(AddPermissionsRequest{Request: Permissions{GranteeCapabilities: grants}})
+	if err != nil {
+		return Permissions{}, err
+	}
+	retval := PermissionsResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.Permissions, err
+}
+
+// removes each rule of grants from the project's default permissions, the server takes one rule per request
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#delete_default_permission
+func (api *API) DeleteDefaultPermissions(siteId, projectId string, resource PermissionResource, grants ...GranteeCapabilities) error {
+	return api.DeleteDefaultPermissionsContext(context.Background(), siteId, projectId, resource, grants...)
+}
+
+func (api *API) DeleteDefaultPermissionsContext(ctx context.Context, siteId, projectId string, resource PermissionResource, grants ...GranteeCapabilities) error {
+	for _, grant := range grants {
+		var grantee string
+		switch {
+		case grant.Group != nil:
+			grantee = "groups/" + grant.Group.ID
+		case grant.User != nil:
+			grantee = "users/" + grant.User.ID
+		default:
+			return errors.New("default permission rules need a group or a user")
+		}
+		for _, capability := range grant.Capabilities.Capabilities {
+			requestUrl := fmt.Sprintf("%s/%s/%s/%s", api.defaultPermissionsUrl(siteId, projectId, resource), grantee, capability.Name, capability.Mode)
+			if err := api.delete(ctx, requestUrl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (api *API) defaultPermissionsUrl(siteId, projectId string, resource PermissionResource) string {
+	return fmt.Sprintf("%s/api/%s/sites/%s/projects/%s/default-permissions/%s", api.Server, api.Version, siteId, projectId, resource)
+}
+
+// ErrContentPermissionsNotApplied is returned when the server answered a content permissions change with
+// another mode, e.g. because the project is nested in a locked project
+var ErrContentPermissionsNotApplied = errors.New("the project's content permissions weren't changed")
+
+// changes who manages the permissions of the project's content and checks the server applied the mode
+func (api *API) SetProjectContentPermissions(siteId, projectId string, mode ContentPermissions) (Project, error) {
+	return api.SetProjectContentPermissionsContext(context.Background(), siteId, projectId, mode)
+}
+
+func (api *API) SetProjectContentPermissionsContext(ctx context.Context, siteId, projectId string, mode ContentPermissions) (Project, error) {
+	if !mode.Valid() {
+		return Project{}, fmt.Errorf("unknown content permissions mode '%s'", mode)
+	}
+	project, err := api.UpdateProjectContext(ctx, siteId, projectId, ProjectUpdate{ContentPermissions: &mode})
+	if err != nil {
+		return project, err
+	}
+	if project.ContentPermissions != mode {
+		return project, fmt.Errorf("%w: asked for %s, the project is %s", ErrContentPermissionsNotApplied, mode, project.ContentPermissions)
+	}
+	return project, nil
+}
+
+// locks the permissions of the project's content to its default permissions, includeNested locks the nested projects too
+func (api *API) LockProject(siteId, projectId string, includeNested bool) (Project, error) {
+	return api.LockProjectContext(context.Background(), siteId, projectId, includeNested)
+}
+
+func (api *API) LockProjectContext(ctx context.Context, siteId, projectId string, includeNested bool) (Project, error) {
+	mode := ContentPermissionsLockedToProjectWithoutNested
+	if includeNested {
+		mode = ContentPermissionsLockedToProject
+	}
+	return api.SetProjectContentPermissionsContext(ctx, siteId, projectId, mode)
+}
+
+// lets the owners of the project's content manage its permissions again
+func (api *API) UnlockProject(siteId, projectId string) (Project, error) {
+	return api.UnlockProjectContext(context.Background(), siteId, projectId)
+}
+
+func (api *API) UnlockProjectContext(ctx context.Context, siteId, projectId string) (Project, error) {
+	return api.SetProjectContentPermissionsContext(ctx, siteId, projectId, ContentPermissionsManagedByOwner)
+}
+
+// the mode the server reports for the project, which for a project nested in a locked one is the parent's
+func (api *API) GetProjectContentPermissions(siteId, projectId string) (ContentPermissions, error) {
+	return api.GetProjectContentPermissionsContext(context.Background(), siteId, projectId)
+}
+
+func (api *API) GetProjectContentPermissionsContext(ctx context.Context, siteId, projectId string) (ContentPermissions, error) {
+	project, err := api.GetProjectByIDContext(ctx, siteId, projectId)
+	return project.ContentPermissions, err
+}
@@ -0,0 +1,109 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SessionPool hands out APIs signed in to individual sites, for services working across many sites of one
+// server. Entries sign in lazily through the template's CredentialProvider and sign in again when their token
+// is close to expiring.
+type SessionPool struct {
+	template API
+	mu       sync.Mutex
+	entries  map[string]*poolEntry
+}
+
+type poolEntry struct {
+	mu  sync.Mutex
+	api *API
+}
+
+// NewSessionPool creates a pool whose APIs are copies of template, which needs a CredentialProvider
+func NewSessionPool(template API) *SessionPool {
+	return &SessionPool{template: template, entries: map[string]*poolEntry{}}
+}
+
+// Get returns the API for the site with the given contentUrl, signing it in first if needed
+func (p *SessionPool) Get(contentUrl string) (*API, error) {
+	return p.GetContext(context.Background(), contentUrl)
+}
+
+// GetContext is like Get, ctx applies to the sign in
+func (p *SessionPool) GetContext(ctx context.Context, contentUrl string) (*API, error) {
+	p.mu.Lock()
+	entry, ok := p.entries[contentUrl]
+	if !ok {
+		entry = &poolEntry{}
+		p.entries[contentUrl] = entry
+	}
+	p.mu.Unlock()
+
+	// signing in happens under the entry's lock only, so one slow site doesn't hold up the others
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.api == nil {
+		api := p.template
+		// the copy must not share the template's session
+		api.session = newSession()
+		api.AuthToken = ""
+		entry.api = &api
+	}
+	if time.Until(entry.api.TokenExpiresAt()) <= DefaultTokenRefreshMargin {
+		if err := entry.api.SigninWithCredentialProviderContext(ctx, contentUrl); err != nil {
+			return nil, err
+		}
+	}
+	return entry.api, nil
+}
+
+// Remove signs the site's API out and drops it from the pool
+func (p *SessionPool) Remove(contentUrl string) error {
+	return p.RemoveContext(context.Background(), contentUrl)
+}
+
+// RemoveContext is like Remove, ctx applies to the sign out
+func (p *SessionPool) RemoveContext(ctx context.Context, contentUrl string) error {
+	p.mu.Lock()
+	entry, ok := p.entries[contentUrl]
+	delete(p.entries, contentUrl)
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.api == nil || !entry.api.TokenValid() {
+		return nil
+	}
+	return entry.api.SignoutContext(ctx)
+}
+
+// Close signs out every API of the pool and returns the first error encountered
+func (p *SessionPool) Close() error {
+	p.mu.Lock()
+	contentUrls := make([]string, 0, len(p.entries))
+	for contentUrl := range p.entries {
+		contentUrls = append(contentUrls, contentUrl)
+	}
+	p.mu.Unlock()
+	var firstErr error
+	for _, contentUrl := range contentUrls {
+		if err := p.Remove(contentUrl); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,158 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// separates the project names of a path, e.g. "Finance/EMEA/Reporting"
+const ProjectPathSeparator = "/"
+
+// a project with its place in the hierarchy
+type ProjectNode struct {
+	Project
+	// the names from the top level project down to this one, joined by ProjectPathSeparator
+	Path     string
+	Parent   *ProjectNode
+	Children []*ProjectNode
+}
+
+// the nested projects of a site, children are sorted by name
+type ProjectTree struct {
+	// top level projects, and projects whose parent the signed in user can't see
+	Roots []*ProjectNode
+	byID  map[string]*ProjectNode
+}
+
+// Find returns the node of the project with the id, nil when the tree doesn't have it
+func (tree ProjectTree) Find(projectId string) *ProjectNode {
+	return tree.byID[projectId]
+}
+
+// FindPath returns the node at the path, names are compared exactly. Nil when no project has the path.
+func (tree ProjectTree) FindPath(path string) *ProjectNode {
+	var node *ProjectNode
+	children := tree.Roots
+	for _, name := range splitProjectPath(path) {
+		node = nil
+		for _, child := range children {
+			if child.Name == name {
+				node = child
+				break
+			}
+		}
+		if node == nil {
+			return nil
+		}
+		children = node.Children
+	}
+	return node
+}
+
+// Walk calls fn for every project, parents before their children
+func (tree ProjectTree) Walk(fn func(node *ProjectNode)) {
+	var walk func(nodes []*ProjectNode)
+	walk = func(nodes []*ProjectNode) {
+		for _, node := range nodes {
+			fn(node)
+			walk(node.Children)
+		}
+	}
+	walk(tree.Roots)
+}
+
+// fetches every project of the site and links them to their parents
+func (api *API) GetProjectTree(siteId string) (ProjectTree, error) {
+	return api.GetProjectTreeContext(context.Background(), siteId)
+}
+
+func (api *API) GetProjectTreeContext(ctx context.Context, siteId string) (ProjectTree, error) {
+	projects, err := api.QueryProjectsContext(ctx, siteId)
+	if err != nil {
+		return ProjectTree{}, err
+	}
+	return buildProjectTree(projects), nil
+}
+
+// returns the project at path, e.g. "Finance/EMEA/Reporting". With createMissing the projects missing along the
+// path are created, otherwise they fail the lookup with ErrNotFound.
+func (api *API) ResolveProjectPath(siteId, path string, createMissing bool) (Project, error) {
+	return api.ResolveProjectPathContext(context.Background(), siteId, path, createMissing)
+}
+
+func (api *API) ResolveProjectPathContext(ctx context.Context, siteId, path string, createMissing bool) (Project, error) {
+	names := splitProjectPath(path)
+	if len(names) == 0 {
+		return Project{}, fmt.Errorf("empty project path '%s'", path)
+	}
+	tree, err := api.GetProjectTreeContext(ctx, siteId)
+	if err != nil {
+		return Project{}, err
+	}
+	project := Project{}
+	for i := range names {
+		prefix := strings.Join(names[:i+1], ProjectPathSeparator)
+		if node := tree.FindPath(prefix); node != nil {
+			project = node.Project
+			continue
+		}
+		if !createMissing {
+			return Project{}, fmt.Errorf("Project Path '%s' %w", prefix, ErrNotFound)
+		}
+		if project, err = api.EnsureProjectContext(ctx, siteId, Project{Name: names[i], ParentProjectID: project.ID}); err != nil {
+			return Project{}, err
+		}
+		api.loggerFor(ctx).Debugf("Created project %s on siteId %s", prefix, siteId)
+	}
+	return project, nil
+}
+
+func buildProjectTree(projects []Project) ProjectTree {
+	sorted := make([]Project, len(projects))
+	copy(sorted, projects)
+	sort.Stable(ProjectByName(sorted))
+
+	tree := ProjectTree{Roots: []*ProjectNode{}, byID: map[string]*ProjectNode{}}
+	for _, project := range sorted {
+		tree.byID[project.ID] = &ProjectNode{Project: project, Children: []*ProjectNode{}}
+	}
+	for _, project := range sorted {
+		node := tree.byID[project.ID]
+		if parent, ok := tree.byID[project.ParentProjectID]; ok && project.ParentProjectID != "" {
+			node.Parent = parent
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Roots = append(tree.Roots, node)
+		}
+	}
+	tree.Walk(func(node *ProjectNode) {
+		node.Path = node.Name
+		if node.Parent != nil {
+			node.Path = node.Parent.Path + ProjectPathSeparator + node.Name
+		}
+	})
+	return tree
+}
+
+func splitProjectPath(path string) []string {
+	names := []string{}
+	for _, name := range strings.Split(path, ProjectPathSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
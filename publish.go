@@ -0,0 +1,234 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the most a single publish request may carry, larger files go to the server in chunks through a file upload
+const maxSinglePublishSize = 64 << 20
+
+var errUploadNotReplayable = errors.New("the upload was sent already and its reader can't seek back to send it again")
+
+// a request body streamed from content, between the multipart headers and trailer kept in memory
+type uploadBody struct {
+	prefix  []byte
+	content io.Reader
+	size    int64
+	suffix  []byte
+	// where content started, to send it again on a retry, -1 when content can't seek
+	start int64
+	sent  bool
+}
+
+func newUploadBody(prefix []byte, content io.Reader, size int64, suffix []byte) *uploadBody {
+	upload := &uploadBody{prefix: prefix, content: content, size: size, suffix: suffix, start: -1}
+	if seeker, ok := content.(io.Seeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			upload.start = offset
+		}
+	}
+	return upload
+}
+
+// a nil upload means the request has an in memory payload, which can always be sent again
+func (upload *uploadBody) replayable() bool {
+	return upload == nil || !upload.sent || upload.start >= 0
+}
+
+func (upload *uploadBody) reader() (io.Reader, int64, error) {
+	if upload.sent {
+		if upload.start < 0 {
+			return nil, 0, errUploadNotReplayable
+		}
+		if _, err := upload.content.(io.Seeker).Seek(upload.start, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+	}
+	upload.sent = true
+	size := int64(len(upload.prefix)) + upload.size + int64(len(upload.suffix))
+	return io.MultiReader(bytes.NewReader(upload.prefix), io.LimitReader(upload.content, upload.size), bytes.NewReader(upload.suffix)), size, nil
+}
+
+// the multipart/mixed request_payload part followed by the headers of the file part, and the trailer
+// that goes after the file. Without a filePart the prefix is the whole payload.
+func (api *API) multipartParts(requestPayload []byte, filePart string, fileName string) ([]byte, []byte) {
+	var prefix bytes.Buffer
+	fmt.Fprintf(&prefix, "--%s\r\n", api.Boundary)
+	prefix.WriteString("Content-Disposition: name=\"request_payload\"\r\n")
+	fmt.Fprintf(&prefix, "Content-Type: %s\r\n", api.codec().ContentType())
+	prefix.WriteString("\r\n")
+	prefix.Write(requestPayload)
+	if filePart == "" {
+		fmt.Fprintf(&prefix, "\r\n--%s--\r\n", api.Boundary)
+		return prefix.Bytes(), nil
+	}
+	fmt.Fprintf(&prefix, "\r\n--%s\r\n", api.Boundary)
+	fmt.Fprintf(&prefix, "Content-Disposition: name=\"%s\"; filename=\"%s\"\r\n", filePart, fileName)
+	prefix.WriteString("Content-Type: application/octet-stream\r\n")
+	prefix.WriteString("\r\n")
+	return prefix.Bytes(), []byte(fmt.Sprintf("\r\n--%s--\r\n", api.Boundary))
+}
+
+func (api *API) multipartHeaders() map[string]string {
+	headers := make(map[string]string)
+	headers[contentTypeHeader] = fmt.Sprintf("multipart/mixed; boundary=%s", api.Boundary)
+	return headers
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#publish_data_source
+// streams size bytes of r to the server as a datasource of the given type (tds, tdsx, tde or hyper) without
+// holding the file in memory. Files over 64MB are sent in chunks, a negative size sends r in chunks up to EOF.
+func (api *API) PublishDatasourceFrom(siteId string, datasource Datasource, r io.Reader, size int64, datasourceType string, options DatasourcePublishOptions) (*Datasource, error) {
+	return api.PublishDatasourceFromContext(context.Background(), siteId, datasource, r, size, datasourceType, options)
+}
+
+func (api *API) PublishDatasourceFromContext(ctx context.Context, siteId string, datasource Datasource, r io.Reader, size int64, datasourceType string, options DatasourcePublishOptions) (*Datasource, error) {
+	requestPayload, err := api.codec().Marshal(DatasourceCreateRequest{Request: datasource})
+	if err != nil {
+		return nil, err
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources?datasourceType=%s&overwrite=%v", api.Server, api.Version, siteId, datasourceType, options.Overwrite)
+	if options.UseRemoteQueryAgent {
+		requestUrl += "&useRemoteQueryAgent=true"
+	}
+	retval := PublishDatasourceResponse{}
+	err = api.publishFrom(ctx, siteId, requestUrl, requestPayload, "tableau_datasource", datasource.Name+"."+datasourceType, r, size, &retval)
+	return &retval.Datasource, err
+}
+
+// publishes the datasource file at path, its extension gives the datasource type
+func (api *API) PublishDatasourceFile(siteId string, datasource Datasource, path string, options DatasourcePublishOptions) (*Datasource, error) {
+	return api.PublishDatasourceFileContext(context.Background(), siteId, datasource, path, options)
+}
+
+func (api *API) PublishDatasourceFileContext(ctx context.Context, siteId string, datasource Datasource, path string, options DatasourcePublishOptions) (*Datasource, error) {
+	file, size, err := openPublishFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return api.PublishDatasourceFromContext(ctx, siteId, datasource, file, size, publishFileType(path), options)
+}
+
+// publishes a file sent with an Uploader as a datasource of the given type
+func (api *API) PublishDatasourceUpload(siteId string, datasource Datasource, uploadSessionId string, datasourceType string, options DatasourcePublishOptions) (*Datasource, error) {
+	return api.PublishDatasourceUploadContext(context.Background(), siteId, datasource, uploadSessionId, datasourceType, options)
+}
+
+func (api *API) PublishDatasourceUploadContext(ctx context.Context, siteId string, datasource Datasource, uploadSessionId string, datasourceType string, options DatasourcePublishOptions) (*Datasource, error) {
+	requestPayload, err := api.codec().Marshal(DatasourceCreateRequest{Request: datasource})
+	if err != nil {
+		return nil, err
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources?datasourceType=%s&overwrite=%v", api.Server, api.Version, siteId, datasourceType, options.Overwrite)
+	if options.UseRemoteQueryAgent {
+		requestUrl += "&useRemoteQueryAgent=true"
+	}
+	retval := PublishDatasourceResponse{}
+	err = api.commitUpload(withOperation(ctx, operationPublish), requestUrl, requestPayload, uploadSessionId, &retval)
+	return &retval.Datasource, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#publish_workbook
+// streams size bytes of r to the server as a workbook of the given type (twb or twbx) without holding the
+// file in memory. Files over 64MB are sent in chunks, a negative size sends r in chunks up to EOF.
+func (api *API) PublishWorkbookFrom(siteId string, workbook Workbook, r io.Reader, size int64, workbookType string, overwrite bool) (*Workbook, error) {
+	return api.PublishWorkbookFromContext(context.Background(), siteId, workbook, r, size, workbookType, overwrite)
+}
+
+func (api *API) PublishWorkbookFromContext(ctx context.Context, siteId string, workbook Workbook, r io.Reader, size int64, workbookType string, overwrite bool) (*Workbook, error) {
+	requestPayload, err := api.codec().Marshal(WorkbookCreateRequest{Request: workbook})
+	if err != nil {
+		return nil, err
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks?workbookType=%s&overwrite=%v", api.Server, api.Version, siteId, workbookType, overwrite)
+	retval := PublishWorkbookResponse{}
+	err = api.publishFrom(ctx, siteId, requestUrl, requestPayload, "tableau_workbook", workbook.Name+"."+workbookType, r, size, &retval)
+	return &retval.Workbook, err
+}
+
+// publishes the workbook file at path, its extension gives the workbook type
+func (api *API) PublishWorkbookFile(siteId string, workbook Workbook, path string, overwrite bool) (*Workbook, error) {
+	return api.PublishWorkbookFileContext(context.Background(), siteId, workbook, path, overwrite)
+}
+
+func (api *API) PublishWorkbookFileContext(ctx context.Context, siteId string, workbook Workbook, path string, overwrite bool) (*Workbook, error) {
+	file, size, err := openPublishFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return api.PublishWorkbookFromContext(ctx, siteId, workbook, file, size, publishFileType(path), overwrite)
+}
+
+// publishes a file sent with an Uploader as a workbook of the given type
+func (api *API) PublishWorkbookUpload(siteId string, workbook Workbook, uploadSessionId string, workbookType string, overwrite bool) (*Workbook, error) {
+	return api.PublishWorkbookUploadContext(context.Background(), siteId, workbook, uploadSessionId, workbookType, overwrite)
+}
+
+func (api *API) PublishWorkbookUploadContext(ctx context.Context, siteId string, workbook Workbook, uploadSessionId string, workbookType string, overwrite bool) (*Workbook, error) {
+	requestPayload, err := api.codec().Marshal(WorkbookCreateRequest{Request: workbook})
+	if err != nil {
+		return nil, err
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks?workbookType=%s&overwrite=%v", api.Server, api.Version, siteId, workbookType, overwrite)
+	retval := PublishWorkbookResponse{}
+	err = api.commitUpload(withOperation(ctx, operationPublish), requestUrl, requestPayload, uploadSessionId, &retval)
+	return &retval.Workbook, err
+}
+
+func openPublishFile(path string) (*os.File, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+func publishFileType(path string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+func (api *API) publishFrom(ctx context.Context, siteId string, requestUrl string, requestPayload []byte, filePart string, fileName string, r io.Reader, size int64, result interface{}) error {
+	ctx = withOperation(ctx, operationPublish)
+	if size >= 0 && size <= maxSinglePublishSize {
+		prefix, suffix := api.multipartParts(requestPayload, filePart, fileName)
+		_, err := api.sendRequest(ctx, requestUrl, POST, nil, newUploadBody(prefix, r, size, suffix), result, api.multipartHeaders())
+		return err
+	}
+	uploadSessionId, err := api.NewUploader(siteId).UploadContext(ctx, r, size)
+	if err != nil {
+		return err
+	}
+	return api.commitUpload(ctx, requestUrl, requestPayload, uploadSessionId, result)
+}
+
+// publishes the file of an upload session, the request only carries the payload describing the content
+func (api *API) commitUpload(ctx context.Context, requestUrl string, requestPayload []byte, uploadSessionId string, result interface{}) error {
+	payload, _ := api.multipartParts(requestPayload, "", "")
+	return api.makeRequest(ctx, requestUrl+"&uploadSessionId="+url.QueryEscape(uploadSessionId), POST, payload, result, api.multipartHeaders())
+}
@@ -0,0 +1,197 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryOption adds parameters such as filter or sort expressions to the request of a list method
+type QueryOption interface {
+	apply(values url.Values)
+}
+
+type queryOptionFunc func(values url.Values)
+
+func (f queryOptionFunc) apply(values url.Values) {
+	f(values)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_concepts_filtering_and_sorting.htm
+// e.g. WithFilter("status:eq:Failed,jobType:eq:refresh_extracts"), combined with the other filters given
+func WithFilter(expression string) QueryOption {
+	return queryOptionFunc(func(values url.Values) {
+		addFilter(values, expression)
+	})
+}
+
+func addFilter(values url.Values, expression string) {
+	if expression == "" {
+		return
+	}
+	if existing := values.Get("filter"); existing != "" {
+		expression = existing + "," + expression
+	}
+	values.Set("filter", expression)
+}
+
+// e.g. WithSort("createdAt:desc")
+func WithSort(expression string) QueryOption {
+	return queryOptionFunc(func(values url.Values) {
+		values.Set("sort", expression)
+	})
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_concepts_fields.htm
+func WithFields(expression string) QueryOption {
+	return queryOptionFunc(func(values url.Values) {
+		values.Set("fields", expression)
+	})
+}
+
+// FilterBuilder builds a filter expression, e.g. Filter().Eq("name", name).Gte("updatedAt", since).
+// Filters given to a list method more than once are combined. The filter syntax has no escaping, so an
+// expression whose value holds a comma is left out and the list holds more than asked for, callers that may
+// pass such values have to match the results themselves.
+type FilterBuilder struct {
+	expressions []string
+}
+
+func Filter() *FilterBuilder {
+	return &FilterBuilder{}
+}
+
+func (f *FilterBuilder) add(field, operator string, value string) *FilterBuilder {
+	// the values of an in list are checked one by one
+	if operator != "in" && !filterable(value) {
+		return f
+	}
+	f.expressions = append(f.expressions, field+":"+operator+":"+value)
+	return f
+}
+
+// whether value can be sent in a filter expression, where commas separate the expressions
+func filterable(value string) bool {
+	return !strings.Contains(value, ",")
+}
+
+func (f *FilterBuilder) Eq(field string, value interface{}) *FilterBuilder {
+	return f.add(field, "eq", filterValue(value))
+}
+
+// case insensitive equality, for names
+func (f *FilterBuilder) CiEq(field string, value interface{}) *FilterBuilder {
+	return f.add(field, "cieq", filterValue(value))
+}
+
+func (f *FilterBuilder) Gt(field string, value interface{}) *FilterBuilder {
+	return f.add(field, "gt", filterValue(value))
+}
+
+func (f *FilterBuilder) Gte(field string, value interface{}) *FilterBuilder {
+	return f.add(field, "gte", filterValue(value))
+}
+
+func (f *FilterBuilder) Lt(field string, value interface{}) *FilterBuilder {
+	return f.add(field, "lt", filterValue(value))
+}
+
+func (f *FilterBuilder) Lte(field string, value interface{}) *FilterBuilder {
+	return f.add(field, "lte", filterValue(value))
+}
+
+// for fields holding several values such as tags
+func (f *FilterBuilder) Has(field string, value interface{}) *FilterBuilder {
+	return f.add(field, "has", filterValue(value))
+}
+
+func (f *FilterBuilder) In(field string, values ...interface{}) *FilterBuilder {
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		if !filterable(filterValue(value)) {
+			return f
+		}
+		formatted = append(formatted, filterValue(value))
+	}
+	return f.add(field, "in", "["+strings.Join(formatted, ",")+"]")
+}
+
+func (f *FilterBuilder) String() string {
+	return strings.Join(f.expressions, ",")
+}
+
+func (f *FilterBuilder) apply(values url.Values) {
+	addFilter(values, f.String())
+}
+
+// dates are compared in UTC and ISO 8601, e.g. 2016-05-04T21:24:49Z
+func filterValue(value interface{}) string {
+	if date, ok := value.(time.Time); ok {
+		return date.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// SortBuilder builds a sort expression, e.g. Sort().Desc("createdAt").Asc("name")
+type SortBuilder struct {
+	expressions []string
+}
+
+func Sort() *SortBuilder {
+	return &SortBuilder{}
+}
+
+func (s *SortBuilder) Asc(field string) *SortBuilder {
+	s.expressions = append(s.expressions, field+":asc")
+	return s
+}
+
+func (s *SortBuilder) Desc(field string) *SortBuilder {
+	s.expressions = append(s.expressions, field+":desc")
+	return s
+}
+
+func (s *SortBuilder) String() string {
+	return strings.Join(s.expressions, ",")
+}
+
+func (s *SortBuilder) apply(values url.Values) {
+	if len(s.expressions) > 0 {
+		values.Set("sort", s.String())
+	}
+}
+
+// e.g. Fields("_default_", "owner.name")
+func Fields(fields ...string) QueryOption {
+	return WithFields(strings.Join(fields, ","))
+}
+
+func WithPageSize(pageSize int) QueryOption {
+	return queryOptionFunc(func(values url.Values) {
+		values.Set("pageSize", strconv.Itoa(pageSize))
+	})
+}
+
+// appends the paging parameters and query options to requestUrl
+func pagedUrl(requestUrl string, pageNum int, opts []QueryOption) string {
+	values := url.Values{}
+	values.Set("pageSize", strconv.Itoa(PAGESIZE))
+	for _, opt := range opts {
+		opt.apply(values)
+	}
+	values.Set("pageNumber", strconv.Itoa(pageNum))
+	return fmt.Sprintf("%s?%s", requestUrl, values.Encode())
+}
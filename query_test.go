@@ -0,0 +1,85 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestFilterBuilder(t *testing.T) {
+	since := time.Date(2016, 5, 4, 23, 24, 49, 0, time.FixedZone("CEST", 2*60*60))
+	tests := []struct {
+		name   string
+		filter *FilterBuilder
+		want   string
+	}{
+		{"empty", Filter(), ""},
+		{"eq", Filter().Eq("name", "Sales"), "name:eq:Sales"},
+		{"cieq", Filter().CiEq("name", "sales"), "name:cieq:sales"},
+		{"numbers", Filter().Gt("size", 10).Lte("size", 20), "size:gt:10,size:lte:20"},
+		{"dates in UTC", Filter().Gte("updatedAt", since), "updatedAt:gte:2016-05-04T21:24:49Z"},
+		{"lt", Filter().Lt("createdAt", "2020-01-01T00:00:00Z"), "createdAt:lt:2020-01-01T00:00:00Z"},
+		{"has", Filter().Has("tags", "finance"), "tags:has:finance"},
+		{"in", Filter().In("siteRole", "Creator", "Explorer"), "siteRole:in:[Creator,Explorer]"},
+		{"combined", Filter().Eq("projectName", "Default").Eq("name", "Sales"), "projectName:eq:Default,name:eq:Sales"},
+		{"colons in values", Filter().Eq("name", "Q1: Sales"), "name:eq:Q1: Sales"},
+		{"a comma leaves the expression out", Filter().Eq("projectName", "Default").Eq("name", "Sales, EMEA"), "projectName:eq:Default"},
+		{"a comma in a list leaves it out", Filter().In("name", "Sales", "Sales, EMEA").Eq("ownerName", "admin"), "ownerName:eq:admin"},
+		{"only unfilterable values", Filter().CiEq("name", "a,b"), ""},
+	}
+	for _, test := range tests {
+		if got := test.filter.String(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPagedUrl(t *testing.T) {
+	const base = "https://tableau.example.com/api/3.4/sites/abc/projects"
+	tests := []struct {
+		name    string
+		pageNum int
+		opts    []QueryOption
+		want    url.Values
+	}{
+		{"defaults", 1, nil, url.Values{"pageSize": {"100"}, "pageNumber": {"1"}}},
+		{"page size", 3, []QueryOption{WithPageSize(1000)}, url.Values{"pageSize": {"1000"}, "pageNumber": {"3"}}},
+		{"filter and sort", 1, []QueryOption{Filter().Eq("name", "a b"), Sort().Desc("createdAt").Asc("name")},
+			url.Values{"pageSize": {"100"}, "pageNumber": {"1"}, "filter": {"name:eq:a b"}, "sort": {"createdAt:desc,name:asc"}}},
+		{"filters are combined", 1, []QueryOption{WithFilter("status:eq:Failed"), Filter().Eq("jobType", "refresh_extracts")},
+			url.Values{"pageSize": {"100"}, "pageNumber": {"1"}, "filter": {"status:eq:Failed,jobType:eq:refresh_extracts"}}},
+		{"WithFilter after a builder", 1, []QueryOption{Filter().Eq("name", "Sales"), WithFilter("status:eq:Failed")},
+			url.Values{"pageSize": {"100"}, "pageNumber": {"1"}, "filter": {"name:eq:Sales,status:eq:Failed"}}},
+		{"two WithFilters", 1, []QueryOption{WithFilter("status:eq:Failed"), WithFilter("jobType:eq:refresh_extracts")},
+			url.Values{"pageSize": {"100"}, "pageNumber": {"1"}, "filter": {"status:eq:Failed,jobType:eq:refresh_extracts"}}},
+		{"a comma in a value sends no filter", 1, []QueryOption{Filter().Eq("name", "Sales, EMEA")}, url.Values{"pageSize": {"100"}, "pageNumber": {"1"}}},
+		{"an empty filter adds nothing", 1, []QueryOption{Filter()}, url.Values{"pageSize": {"100"}, "pageNumber": {"1"}}},
+		{"fields", 2, []QueryOption{Fields("_default_", "owner.name")},
+			url.Values{"pageSize": {"100"}, "pageNumber": {"2"}, "fields": {"_default_,owner.name"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := url.Parse(pagedUrl(base, test.pageNum, test.opts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Scheme+"://"+parsed.Host+parsed.Path != base {
+				t.Fatalf("pagedUrl changed the url to %s", parsed)
+			}
+			if got := parsed.Query(); got.Encode() != test.want.Encode() {
+				t.Fatalf("query = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
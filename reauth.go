@@ -0,0 +1,55 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// the error code Tableau answers with once a session token expired or was invalidated
+const tokenExpiredErrorCode = "401002"
+
+var errNoSigninCredentials = errors.New("no credentials to sign in again with")
+
+func isTokenExpired(err error) bool {
+	return errors.Is(err, ErrSessionExpired)
+}
+
+func isSigninUrl(requestUrl string) bool {
+	return strings.Contains(requestUrl, "/auth/signin")
+}
+
+// signs in again after the session expired, through api.Reauthenticate, api.CredentialProvider or by
+// replaying the credentials of the last Signin. staleToken is the token the failed request was made with, when another
+// goroutine has replaced it in the meantime there is nothing left to do.
+func (api *API) reauthenticate(ctx context.Context, staleToken string) error {
+	if api.session != nil {
+		api.session.reauthMu.Lock()
+		defer api.session.reauthMu.Unlock()
+		if api.Token() != staleToken {
+			return nil
+		}
+	}
+	if api.Reauthenticate != nil {
+		return api.Reauthenticate(api)
+	}
+	credentials, contentUrl := api.signinState()
+	if api.CredentialProvider != nil {
+		return api.SigninWithCredentialProviderContext(ctx, contentUrl)
+	}
+	if credentials == nil {
+		return errNoSigninCredentials
+	}
+	return api.signin(ctx, *credentials, contentUrl)
+}
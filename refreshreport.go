@@ -0,0 +1,153 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"time"
+)
+
+// one failed extract refresh, joined with the content it refreshed and that content's owner
+type RefreshFailure struct {
+	JobID       string
+	CreatedAt   string
+	EndedAt     string
+	ContentType string
+	ContentID   string
+	ContentName string
+	ProjectID   string
+	ProjectName string
+	OwnerID     string
+	OwnerName   string
+	Notes       string
+}
+
+type RefreshFailureReport struct {
+	Since    time.Time
+	Until    time.Time
+	Failures []RefreshFailure
+}
+
+var refreshFailureCSVHeader = []string{
+	"jobId", "createdAt", "endedAt", "contentType", "contentId", "contentName", "projectId", "projectName", "ownerId", "ownerName", "notes",
+}
+
+// collects the extract refreshes that failed between since and until
+func (api *API) GetRefreshFailureReport(siteId string, since, until time.Time) (RefreshFailureReport, error) {
+	return api.GetRefreshFailureReportContext(context.Background(), siteId, since, until)
+}
+
+func (api *API) GetRefreshFailureReportContext(ctx context.Context, siteId string, since, until time.Time) (RefreshFailureReport, error) {
+	report := RefreshFailureReport{Since: since, Until: until, Failures: []RefreshFailure{}}
+	filter := Filter().Eq("status", JobStatusFailed).Eq("jobType", JobTypeRefreshExtracts).Gte("createdAt", since).Lte("createdAt", until)
+	jobs, err := api.QueryJobsContext(ctx, siteId, filter)
+	if err != nil {
+		return report, err
+	}
+	if len(jobs) == 0 {
+		return report, nil
+	}
+
+	// look everything up once rather than once per job
+	lookup, err := api.newContentLookup(ctx, siteId)
+	if err != nil {
+		return report, err
+	}
+
+	for _, backgroundJob := range jobs {
+		failure := RefreshFailure{JobID: backgroundJob.ID, CreatedAt: backgroundJob.CreatedAt, EndedAt: backgroundJob.EndedAt}
+		job, err := api.QueryJobContext(ctx, siteId, backgroundJob.ID)
+		if err != nil {
+			return report, err
+		}
+		var project *Project
+		var owner *User
+		if refresh := job.ExtractRefreshJob; refresh != nil {
+			failure.Notes = refresh.Notes
+			switch {
+			case refresh.Datasource != nil:
+				failure.ContentType = "datasource"
+				failure.ContentID = refresh.Datasource.ID
+				failure.ContentName = refresh.Datasource.Name
+				datasource := lookup.datasources[refresh.Datasource.ID]
+				project, owner = datasource.Project, datasource.Owner
+			case refresh.Workbook != nil:
+				failure.ContentType = "workbook"
+				failure.ContentID = refresh.Workbook.ID
+				failure.ContentName = refresh.Workbook.Name
+				workbook := lookup.workbooks[refresh.Workbook.ID]
+				project, owner = workbook.Project, workbook.Owner
+			}
+		}
+		if project != nil {
+			failure.ProjectID = project.ID
+			failure.ProjectName = project.Name
+		}
+		if owner != nil {
+			failure.OwnerID = owner.ID
+			failure.OwnerName = lookup.userNames[owner.ID]
+		}
+		report.Failures = append(report.Failures, failure)
+	}
+	return report, nil
+}
+
+// writes the report as CSV with a header row
+func (report RefreshFailureReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(refreshFailureCSVHeader); err != nil {
+		return err
+	}
+	for _, f := range report.Failures {
+		row := []string{f.JobID, f.CreatedAt, f.EndedAt, f.ContentType, f.ContentID, f.ContentName, f.ProjectID, f.ProjectName, f.OwnerID, f.OwnerName, f.Notes}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// datasources, workbooks and user names of a site keyed by id
+type contentLookup struct {
+	datasources map[string]Datasource
+	workbooks   map[string]Workbook
+	userNames   map[string]string
+}
+
+func (api *API) newContentLookup(ctx context.Context, siteId string) (contentLookup, error) {
+	lookup := contentLookup{datasources: map[string]Datasource{}, workbooks: map[string]Workbook{}, userNames: map[string]string{}}
+	datasources, err := api.QueryAllDatasourcesContext(ctx, siteId)
+	if err != nil {
+		return lookup, err
+	}
+	for _, datasource := range datasources {
+		lookup.datasources[datasource.ID] = datasource
+	}
+	workbooks, err := api.QueryWorkbooksContext(ctx, siteId)
+	if err != nil {
+		return lookup, err
+	}
+	for _, workbook := range workbooks {
+		lookup.workbooks[workbook.ID] = workbook
+	}
+	users, err := api.QueryUsersOnSiteContext(ctx, siteId)
+	if err != nil {
+		return lookup, err
+	}
+	for _, user := range users {
+		lookup.userNames[user.ID] = user.Name
+	}
+	return lookup, nil
+}
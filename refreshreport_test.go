@@ -0,0 +1,44 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRefreshFailureReportFilter(t *testing.T) {
+	filter := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter = r.URL.Query().Get("filter")
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="0"/><backgroundJobs/></tsResponse>`))
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 3, 2, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	report, err := api.GetRefreshFailureReport("site", since, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "status:eq:Failed,jobType:eq:refresh_extracts,createdAt:gte:2024-03-01T00:00:00Z,createdAt:lte:2024-03-02T11:00:00Z"
+	if filter != want {
+		t.Fatalf("filtered the jobs on %q, want %q", filter, want)
+	}
+	if len(report.Failures) != 0 {
+		t.Fatalf("report holds %+v, want no failures", report.Failures)
+	}
+}
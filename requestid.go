@@ -0,0 +1,103 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// sent when API.RequestIDHeader is empty
+const DefaultRequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID attaches a correlation id to the calls made with ctx. It is sent as a header, prefixes the
+// client's log lines and is part of the errors the calls return, so failures can be found in the server's logs.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the correlation id attached with WithRequestID, e.g. for trace spans
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// RequestError is returned by calls that carry a correlation id
+type RequestError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%v (request id %s)", e.Err, e.RequestID)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// attaches a generated id to calls without one when api.GenerateRequestIDs is set
+func (api *API) withRequestID(ctx context.Context) (context.Context, string) {
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" && api.GenerateRequestIDs {
+		requestID = newRequestID()
+		ctx = WithRequestID(ctx, requestID)
+	}
+	return ctx, requestID
+}
+
+func (api *API) requestIDHeader() string {
+	if api.RequestIDHeader == "" {
+		return DefaultRequestIDHeader
+	}
+	return api.RequestIDHeader
+}
+
+// prefixes the log lines of a call with its correlation id
+type requestLogger struct {
+	Logger
+	prefix string
+}
+
+func (l requestLogger) Debugf(format string, args ...interface{}) {
+	l.Logger.Debugf(l.prefix+format, args...)
+}
+
+func (l requestLogger) Infof(format string, args ...interface{}) {
+	l.Logger.Infof(l.prefix+format, args...)
+}
+
+func (l requestLogger) Errorf(format string, args ...interface{}) {
+	l.Logger.Errorf(l.prefix+format, args...)
+}
+
+func (api *API) loggerFor(ctx context.Context) Logger {
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		return api.logger()
+	}
+	// the id may contain %, keep it out of the format
+	return requestLogger{Logger: api.logger(), prefix: "[" + strings.ReplaceAll(requestID, "%", "%%") + "] "}
+}
@@ -0,0 +1,56 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"net/http"
+)
+
+// Response is the last HTTP response of a call, filled in for calls made with a context from WithResponse.
+// Body is nil for downloads streamed to a writer.
+type Response struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// answered from API.Cache without asking the server, Header is nil then
+	Cached bool
+}
+
+type responseKey struct{}
+
+// WithResponse records the response of the calls made with ctx into response, e.g. to read the pagination or
+// the filename of the Content-Disposition header, or to debug a payload the models don't decode:
+//
+//	response := &Response{}
+//	datasources, err := api.QueryDatasourcesContext(WithResponse(ctx, response), siteId, "")
+//	log.Printf("%d %s", response.StatusCode, response.Body)
+//
+// When a call sends several requests, e.g. to page through results, response holds the last one.
+func WithResponse(ctx context.Context, response *Response) context.Context {
+	return context.WithValue(ctx, responseKey{}, response)
+}
+
+func captureResponse(ctx context.Context, method, requestUrl string, resp *http.Response, body []byte) {
+	response, ok := ctx.Value(responseKey{}).(*Response)
+	if !ok || response == nil {
+		return
+	}
+	*response = Response{Method: method, URL: requestUrl, Body: body}
+	if resp == nil {
+		response.StatusCode, response.Cached = http.StatusOK, true
+		return
+	}
+	response.StatusCode, response.Header = resp.StatusCode, resp.Header
+}
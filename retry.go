@@ -0,0 +1,156 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy sends a GET again when it fails with 429, 502, 503, 504 or a transient network error. POST, PUT
+// and DELETE requests are only retried when the server can't have acted on them: the connection couldn't be
+// made, or the server answered 429 or 503. Set RetryNonIdempotent to retry them like GETs.
+type RetryPolicy struct {
+	// attempts including the first one, requests aren't retried below 2
+	MaxAttempts int
+	// the wait before the first retry, doubled for every further one and capped at MaxDelay when set
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// the fraction of each wait that is randomized, 0.2 turns a 1s wait into 0.8s to 1.2s
+	Jitter float64
+	// 429 and 503 responses are retried after the wait their Retry-After header asks for when it is longer
+	// than the policy's. A request asked to wait longer than MaxRetryAfter fails with the error right away,
+	// see RetryAfter. Zero waits however long the server asks.
+	MaxRetryAfter time.Duration
+	// also retries POST, PUT and DELETE requests after failures the server may have processed them in, such
+	// as a dropped connection, at the risk of e.g. publishing twice
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is a reasonable policy for bulk jobs
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second, Jitter: 0.2}
+
+// the wait before the given retry, the first retry being 1
+func (p RetryPolicy) delay(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		//nolint:gosec // jitter doesn't need a secure random source
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// whether a request with the method that failed with err should be sent again
+func (p RetryPolicy) retryable(method string, err error) bool {
+	if method == GET || p.RetryNonIdempotent {
+		return isRetryable(err)
+	}
+	return notProcessed(err)
+}
+
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if code, ok := errorStatus(err); ok {
+		return retryableStatus(code)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// failures the server can't have acted on, it was never reached or it turned the request away
+func notProcessed(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if code, ok := errorStatus(err); ok {
+		return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// the http status of an error response
+func errorStatus(err error) (int, bool) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code, true
+	}
+	// tableau error codes start with the http status, e.g. 429000
+	var tErr TError
+	if errors.As(err, &tErr) {
+		if len(tErr.Code) < 3 {
+			return 0, true
+		}
+		code, _ := strconv.Atoi(tErr.Code[:3])
+		return code, true
+	}
+	return 0, false
+}
+
+func (api *API) doRequestWithRetry(ctx context.Context, requestUrl string, method string, payload []byte, upload *uploadBody, result interface{}, headers map[string]string) ([]byte, error) {
+	body, err := api.doRequestThroughBreaker(ctx, requestUrl, method, payload, upload, result, headers)
+	for retry := 1; retry < api.RetryPolicy.MaxAttempts && api.RetryPolicy.retryable(strings.TrimSpace(method), err) && !streamStarted(result) && upload.replayable(); retry++ {
+		delay := api.RetryPolicy.delay(retry)
+		// a rate limited server says how long to back off
+		if retryAfter, ok := RetryAfter(err); ok && retryAfter > delay {
+			if api.RetryPolicy.MaxRetryAfter > 0 && retryAfter > api.RetryPolicy.MaxRetryAfter {
+				return body, err
+			}
+			delay = retryAfter
+		}
+		api.loggerFor(ctx).Infof("t4g retrying in %v after:%v", delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return body, err
+		case <-timer.C:
+		}
+		if api.stats != nil {
+			api.stats.retried()
+		}
+		api.observeRetry(requestUrl, method)
+		body, err = api.doRequestThroughBreaker(ctx, requestUrl, method, payload, upload, result, headers)
+	}
+	return body, err
+}
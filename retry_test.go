@@ -0,0 +1,95 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{"first retry", RetryPolicy{BaseDelay: time.Second}, 1, time.Second},
+		{"doubles", RetryPolicy{BaseDelay: time.Second}, 3, 4 * time.Second},
+		{"uncapped", RetryPolicy{BaseDelay: time.Second}, 6, 32 * time.Second},
+		{"capped", RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, 4, 5 * time.Second},
+		{"capped far out", RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, 1000, 5 * time.Second},
+		{"no base delay", RetryPolicy{}, 3, 0},
+	}
+	for _, test := range tests {
+		if got := test.policy.delay(test.retry); got != test.want {
+			t.Errorf("%s: delay(%d) = %v, want %v", test.name, test.retry, got, test.want)
+		}
+	}
+}
+
+func TestRetryPolicyDelayJitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		if got := policy.delay(1); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("delay(1) = %v, want within 20%% of 1s", got)
+		}
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	resetErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	tests := []struct {
+		name        string
+		err         error
+		get         bool
+		post        bool
+		postOptedIn bool
+	}{
+		{"success", nil, false, false, false},
+		{"canceled", context.Canceled, false, false, false},
+		{"deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), false, false, false},
+		{"429", &StatusError{Code: 429}, true, true, true},
+		{"503", &StatusError{Code: 503}, true, true, true},
+		{"502", &StatusError{Code: 502}, true, false, true},
+		{"504", &StatusError{Code: 504}, true, false, true},
+		{"404", &StatusError{Code: 404}, false, false, false},
+		{"tableau 429", TError{Code: "429000"}, true, true, true},
+		{"tableau 500", TError{Code: "500000"}, false, false, false},
+		{"tableau short code", TError{Code: "4"}, false, false, false},
+		{"dial refused", dialErr, true, true, true},
+		{"connection reset", resetErr, true, false, true},
+		{"eof", fmt.Errorf("post: %w", io.EOF), true, false, true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true, false, true},
+		{"other", errors.New("boom"), false, false, false},
+	}
+	for _, test := range tests {
+		if got := (RetryPolicy{}).retryable(GET, test.err); got != test.get {
+			t.Errorf("%s: GET retryable = %v, want %v", test.name, got, test.get)
+		}
+		for _, method := range []string{POST, PUT, DELETE} {
+			if got := (RetryPolicy{}).retryable(method, test.err); got != test.post {
+				t.Errorf("%s: %s retryable = %v, want %v", test.name, method, got, test.post)
+			}
+			if got := (RetryPolicy{RetryNonIdempotent: true}).retryable(method, test.err); got != test.postOptedIn {
+				t.Errorf("%s: %s retryable with RetryNonIdempotent = %v, want %v", test.name, method, got, test.postOptedIn)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_revisions.htm#get_workbook_revisions
+func (api *API) QueryWorkbookRevisions(siteId, workbookId string) ([]Revision, error) {
+	return api.QueryWorkbookRevisionsContext(context.Background(), siteId, workbookId)
+}
+
+func (api *API) QueryWorkbookRevisionsContext(ctx context.Context, siteId, workbookId string) ([]Revision, error) {
+	return api.queryRevisions(ctx, siteId, "workbooks", workbookId)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_revisions.htm#get_data_source_revisions
+func (api *API) QueryDatasourceRevisions(siteId, datasourceId string) ([]Revision, error) {
+	return api.QueryDatasourceRevisionsContext(context.Background(), siteId, datasourceId)
+}
+
+func (api *API) QueryDatasourceRevisionsContext(ctx context.Context, siteId, datasourceId string) ([]Revision, error) {
+	return api.queryRevisions(ctx, siteId, "datasources", datasourceId)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_revisions.htm#remove_workbook_revision
+func (api *API) DeleteWorkbookRevision(siteId, workbookId string, revisionNumber int) error {
+	return api.DeleteWorkbookRevisionContext(context.Background(), siteId, workbookId, revisionNumber)
+}
+
+func (api *API) DeleteWorkbookRevisionContext(ctx context.Context, siteId, workbookId string, revisionNumber int) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s/revisions/%d", api.Server, api.Version, siteId, workbookId, revisionNumber)
+	return api.delete(ctx, requestUrl)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_revisions.htm#remove_data_source_revision
+func (api *API) DeleteDatasourceRevision(siteId, datasourceId string, revisionNumber int) error {
+	return api.DeleteDatasourceRevisionContext(context.Background(), siteId, datasourceId, revisionNumber)
+}
+
+func (api *API) DeleteDatasourceRevisionContext(ctx context.Context, siteId, datasourceId string, revisionNumber int) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/datasources/%s/revisions/%d", api.Server, api.Version, siteId, datasourceId, revisionNumber)
+	return api.delete(ctx, requestUrl)
+}
+
+// contentType is the path segment of the content, either "workbooks" or "datasources"
+func (api *API) queryRevisions(ctx context.Context, siteId, contentType, contentId string) ([]Revision, error) {
+	totalAvailable := 1
+	revisions := []Revision{}
+	for i := 1; len(revisions) < totalAvailable; i++ {
+		requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/%s/%s/revisions?pageSize=%v&pageNumber=%v", api.Server, api.Version, siteId, contentType, contentId, PAGESIZE, i)
+		headers := make(map[string]string)
+		response := QueryRevisionsResponse{}
+		if err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers); err != nil {
+			return revisions, err
+		}
+		revisions = append(revisions, response.Revisions.Revisions...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return revisions, nil
+}
+
+// deletes all but the newest keep revisions of every workbook and datasource in the project and returns
+// the number of revisions removed. The current revision is never removed.
+func (api *API) PurgeRevisions(siteId, projectId string, keep int) (int, error) {
+	return api.PurgeRevisionsContext(context.Background(), siteId, projectId, keep)
+}
+
+func (api *API) PurgeRevisionsContext(ctx context.Context, siteId, projectId string, keep int) (int, error) {
+	if keep < 1 {
+		return 0, errors.New("at least one revision must be kept")
+	}
+	purged := 0
+	project, err := api.GetProjectByIDContext(ctx, siteId, projectId)
+	if err != nil {
+		return purged, err
+	}
+	// the filter matches nested projects sharing the name too, the project id tells them apart
+	inProject := Filter().Eq("projectName", project.Name)
+	workbooks, err := api.QueryWorkbooksContext(ctx, siteId, inProject)
+	if err != nil {
+		return purged, err
+	}
+	for _, workbook := range workbooks {
+		if workbook.Project == nil || workbook.Project.ID != projectId {
+			continue
+		}
+		revisions, err := api.QueryWorkbookRevisionsContext(ctx, siteId, workbook.ID)
+		if err != nil {
+			return purged, err
+		}
+		for _, revisionNumber := range revisionsToPurge(revisions, keep) {
+			if err = api.DeleteWorkbookRevisionContext(ctx, siteId, workbook.ID, revisionNumber); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+
+	datasources, err := api.QueryAllDatasourcesContext(ctx, siteId, inProject)
+	if err != nil {
+		return purged, err
+	}
+	for _, datasource := range datasources {
+		if datasource.Project == nil || datasource.Project.ID != projectId {
+			continue
+		}
+		revisions, err := api.QueryDatasourceRevisionsContext(ctx, siteId, datasource.ID)
+		if err != nil {
+			return purged, err
+		}
+		for _, revisionNumber := range revisionsToPurge(revisions, keep) {
+			if err = api.DeleteDatasourceRevisionContext(ctx, siteId, datasource.ID, revisionNumber); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+	api.loggerFor(ctx).Infof("Purged %d revisions in project %s of siteId %s", purged, projectId, siteId)
+	return purged, nil
+}
+
+// returns the revision numbers older than the newest keep revisions that have not been deleted yet
+func revisionsToPurge(revisions []Revision, keep int) []int {
+	live := []Revision{}
+	for _, revision := range revisions {
+		if !revision.Deleted {
+			live = append(live, revision)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].RevisionNumber > live[j].RevisionNumber })
+	toPurge := []int{}
+	for i, revision := range live {
+		if i >= keep && !revision.Current {
+			toPurge = append(toPurge, revision.RevisionNumber)
+		}
+	}
+	return toPurge
+}
@@ -0,0 +1,39 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRevisionsToPurge(t *testing.T) {
+	tests := []struct {
+		name      string
+		revisions []Revision
+		keep      int
+		want      []int
+	}{
+		{"none", nil, 1, []int{}},
+		{"fewer than kept", []Revision{{RevisionNumber: 1}, {RevisionNumber: 2, Current: true}}, 3, []int{}},
+		{"oldest first", []Revision{{RevisionNumber: 3, Current: true}, {RevisionNumber: 1}, {RevisionNumber: 2}}, 1, []int{2, 1}},
+		{"keeps the newest", []Revision{{RevisionNumber: 1}, {RevisionNumber: 2}, {RevisionNumber: 3}, {RevisionNumber: 4, Current: true}}, 2, []int{2, 1}},
+		{"deleted revisions don't count", []Revision{{RevisionNumber: 1}, {RevisionNumber: 2, Deleted: true}, {RevisionNumber: 3, Current: true}}, 1, []int{1}},
+		// a current revision that isn't the newest, e.g. after a restore, is never purged
+		{"current is never purged", []Revision{{RevisionNumber: 1, Current: true}, {RevisionNumber: 2}, {RevisionNumber: 3}}, 1, []int{2}},
+	}
+	for _, test := range tests {
+		if got := revisionsToPurge(test.revisions, test.keep); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
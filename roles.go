@@ -0,0 +1,93 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SiteRole is the license level and permissions ceiling of a user on a site
+type SiteRole string
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#add_user_to_site
+const (
+	SiteRoleCreator             SiteRole = "Creator"
+	SiteRoleExplorer            SiteRole = "Explorer"
+	SiteRoleExplorerCanPublish  SiteRole = "ExplorerCanPublish"
+	SiteRoleSiteAdminCreator    SiteRole = "SiteAdministratorCreator"
+	SiteRoleSiteAdminExplorer   SiteRole = "SiteAdministratorExplorer"
+	SiteRoleServerAdministrator SiteRole = "ServerAdministrator"
+	SiteRoleViewer              SiteRole = "Viewer"
+	SiteRoleReadOnly            SiteRole = "ReadOnly"
+	SiteRoleGuest               SiteRole = "Guest"
+	SiteRoleUnlicensed          SiteRole = "Unlicensed"
+)
+
+// the roles of servers before user based licensing, still reported for users that were never migrated
+const (
+	SiteRoleInteractor            SiteRole = "Interactor"
+	SiteRolePublisher             SiteRole = "Publisher"
+	SiteRoleSiteAdministrator     SiteRole = "SiteAdministrator"
+	SiteRoleUnlicensedWithPublish SiteRole = "UnlicensedWithPublish"
+	SiteRoleViewerWithPublish     SiteRole = "ViewerWithPublish"
+)
+
+// ErrInvalidSiteRole is returned before any request is made when a site role isn't one Tableau knows
+var ErrInvalidSiteRole = errors.New("invalid site role")
+
+// Valid reports whether the server accepts the role, which doesn't include the legacy roles it only reports
+func (role SiteRole) Valid() bool {
+	switch role {
+	case SiteRoleCreator, SiteRoleExplorer, SiteRoleExplorerCanPublish, SiteRoleSiteAdminCreator, SiteRoleSiteAdminExplorer,
+		SiteRoleServerAdministrator, SiteRoleViewer, SiteRoleReadOnly, SiteRoleGuest, SiteRoleUnlicensed:
+		return true
+	}
+	return false
+}
+
+// Licensed reports whether a user with the role consumes a seat
+func (role SiteRole) Licensed() bool {
+	return role != SiteRoleUnlicensed && role != SiteRoleUnlicensedWithPublish && role != ""
+}
+
+// AuthSetting is how a user signs in
+type AuthSetting string
+
+const (
+	AuthSettingServerDefault    AuthSetting = "ServerDefault"
+	AuthSettingSAML             AuthSetting = "SAML"
+	AuthSettingOpenID           AuthSetting = "OpenID"
+	AuthSettingTableauIDWithMFA AuthSetting = "TableauIDWithMFA"
+)
+
+// ErrInvalidAuthSetting is returned before any request is made when an auth setting isn't one Tableau knows
+var ErrInvalidAuthSetting = errors.New("invalid auth setting")
+
+func (setting AuthSetting) Valid() bool {
+	switch setting {
+	case AuthSettingServerDefault, AuthSettingSAML, AuthSettingOpenID, AuthSettingTableauIDWithMFA:
+		return true
+	}
+	return false
+}
+
+// checks the role and auth setting given to a request, empty values leave them to the server and pass
+func validateRoles(siteRole SiteRole, authSetting AuthSetting) error {
+	if siteRole != "" && !siteRole.Valid() {
+		return fmt.Errorf("%w: '%s'", ErrInvalidSiteRole, siteRole)
+	}
+	if authSetting != "" && !authSetting.Valid() {
+		return fmt.Errorf("%w: '%s'", ErrInvalidAuthSetting, authSetting)
+	}
+	return nil
+}
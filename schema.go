@@ -0,0 +1,302 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type SchemaIssueKind string
+
+const (
+	SchemaUnknownElement   SchemaIssueKind = "unknown-element"
+	SchemaUnknownAttribute SchemaIssueKind = "unknown-attribute"
+	SchemaMissingElement   SchemaIssueKind = "missing-element"
+	SchemaVersionMismatch  SchemaIssueKind = "version-mismatch"
+	// the body isn't a tsResponse document, e.g. the html login page of a proxy
+	SchemaUnexpectedRoot SchemaIssueKind = "unexpected-root"
+)
+
+// a difference between a response and the model it was decoded into
+type SchemaIssue struct {
+	Kind SchemaIssueKind
+	// slash separated element path from tsResponse, attributes are appended with @
+	Path   string
+	Detail string
+}
+
+func (i SchemaIssue) String() string {
+	if i.Detail != "" {
+		return fmt.Sprintf("%s %s: %s", i.Kind, i.Path, i.Detail)
+	}
+	return fmt.Sprintf("%s %s", i.Kind, i.Path)
+}
+
+var schemaLocationVersion = regexp.MustCompile(`ts-api_(\d+)_(\d+)\.xsd`)
+var requestUrlVersion = regexp.MustCompile(`/api/(\d+\.\d+)/`)
+
+// checks a decoded response when api.ValidateResponses is on and hands what it found to api.OnSchemaIssues,
+// or prints it in debug mode
+func (api *API) validateResponse(ctx context.Context, requestUrl string, body []byte, result interface{}) {
+	if !api.ValidateResponses || result == nil || len(body) == 0 {
+		return
+	}
+	expectedVersion := ""
+	if match := requestUrlVersion.FindStringSubmatch(requestUrl); match != nil {
+		expectedVersion = match[1]
+	}
+	issues := validateSchema(body, result, expectedVersion)
+	if len(issues) == 0 {
+		return
+	}
+	if api.OnSchemaIssues != nil {
+		api.OnSchemaIssues(requestUrl, issues)
+		return
+	}
+	for _, issue := range issues {
+		api.loggerFor(ctx).Infof("t4g schema %s: %s", requestUrl, issue)
+	}
+}
+
+// SchemaError is returned instead of a zero valued result when api.StrictResponses is on and a response isn't
+// a tsResponse document or lacks elements its model expects
+type SchemaError struct {
+	URL    string
+	Issues []SchemaIssue
+}
+
+func (e *SchemaError) Error() string {
+	issues := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		issues = append(issues, issue.String())
+	}
+	return fmt.Sprintf("response from %s doesn't match its model: %s", e.URL, strings.Join(issues, "; "))
+}
+
+// the root element check and missing elements of validateSchema, unknown elements and attributes are left
+// to ValidateResponses as servers newer than the models add them all the time
+func (api *API) checkStrict(requestUrl string, body []byte, result interface{}) error {
+	if _, ok := api.codec().(XMLCodec); !ok || !api.StrictResponses || result == nil {
+		return nil
+	}
+	if issue := checkRoot(body); issue != nil {
+		return &SchemaError{URL: requestUrl, Issues: []SchemaIssue{*issue}}
+	}
+	missing := []SchemaIssue{}
+	for _, issue := range validateSchema(body, result, "") {
+		if issue.Kind == SchemaMissingElement {
+			missing = append(missing, issue)
+		}
+	}
+	if len(missing) > 0 {
+		return &SchemaError{URL: requestUrl, Issues: missing}
+	}
+	return nil
+}
+
+func checkRoot(body []byte) *SchemaIssue {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return &SchemaIssue{Kind: SchemaUnexpectedRoot, Detail: fmt.Sprintf("no tsResponse element: %v", err)}
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "tsResponse" {
+				return &SchemaIssue{Kind: SchemaUnexpectedRoot, Path: start.Name.Local, Detail: "expected tsResponse"}
+			}
+			return nil
+		}
+	}
+}
+
+// compares the elements and attributes of body against what the model behind result can hold. Elements the model
+// doesn't know about would be silently dropped by encoding/xml, elements it expects but the body lacks come back zero valued.
+func validateSchema(body []byte, result interface{}, expectedVersion string) []SchemaIssue {
+	known := newSchemaModel()
+	known.addStruct("tsResponse", reflect.TypeOf(result))
+
+	issues := []SchemaIssue{}
+	seen := map[string]bool{}
+	reported := map[string]bool{}
+	path := []string{}
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return append(issues, SchemaIssue{Kind: SchemaUnknownElement, Path: strings.Join(path, "/"), Detail: err.Error()})
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			elementPath := strings.Join(path, "/")
+			seen[elementPath] = true
+			if len(path) == 1 {
+				issues = append(issues, checkSchemaVersion(t, expectedVersion)...)
+			}
+			if known.opaque(elementPath) {
+				continue
+			}
+			if !known.elements[elementPath] && !reported[elementPath] {
+				reported[elementPath] = true
+				issues = append(issues, SchemaIssue{Kind: SchemaUnknownElement, Path: elementPath})
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Space != "" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				attrPath := elementPath + "@" + attr.Name.Local
+				if known.elements[elementPath] && !known.attrs[attrPath] && !reported[attrPath] {
+					reported[attrPath] = true
+					issues = append(issues, SchemaIssue{Kind: SchemaUnknownAttribute, Path: attrPath})
+				}
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		}
+	}
+
+	// only the top level elements are required, anything below them is optional in the ts-api schema
+	for _, child := range known.children("tsResponse") {
+		if !seen[child] {
+			issues = append(issues, SchemaIssue{Kind: SchemaMissingElement, Path: child})
+		}
+	}
+	return issues
+}
+
+func checkSchemaVersion(root xml.StartElement, expectedVersion string) []SchemaIssue {
+	if expectedVersion == "" {
+		return nil
+	}
+	for _, attr := range root.Attr {
+		if attr.Name.Local != "schemaLocation" {
+			continue
+		}
+		match := schemaLocationVersion.FindStringSubmatch(attr.Value)
+		if match == nil {
+			return nil
+		}
+		version := match[1] + "." + match[2]
+		if version != expectedVersion {
+			return []SchemaIssue{{Kind: SchemaVersionMismatch, Path: root.Name.Local, Detail: fmt.Sprintf("response uses ts-api %s, requested %s", version, expectedVersion)}}
+		}
+	}
+	return nil
+}
+
+// the element and attribute paths a model type can decode
+type schemaModel struct {
+	elements map[string]bool
+	attrs    map[string]bool
+	// paths decoded by custom unmarshalers or innerxml, anything below them is accepted
+	opaquePaths []string
+}
+
+func newSchemaModel() *schemaModel {
+	return &schemaModel{elements: map[string]bool{}, attrs: map[string]bool{}}
+}
+
+var xmlUnmarshalerType = reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()
+
+func (m *schemaModel) addStruct(path string, t reflect.Type) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		if t.Kind() == reflect.Interface {
+			return
+		}
+		t = t.Elem()
+	}
+	m.elements[path] = true
+	if reflect.PtrTo(t).Implements(xmlUnmarshalerType) {
+		m.opaquePaths = append(m.opaquePaths, path)
+		return
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	// recursive models such as nested projects would otherwise never end
+	if strings.Count(path, "/") > 16 {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		m.addField(path, t.Field(i))
+	}
+}
+
+func (m *schemaModel) addField(path string, field reflect.StructField) {
+	tag := field.Tag.Get("xml")
+	if tag == "-" || field.Name == "XMLName" || (field.PkgPath != "" && !field.Anonymous) {
+		return
+	}
+	name, options := tag, ""
+	if comma := strings.Index(tag, ","); comma >= 0 {
+		name, options = tag[:comma], tag[comma+1:]
+	}
+	if field.Anonymous && name == "" {
+		m.addStruct(path, field.Type)
+		return
+	}
+	if name == "" {
+		name = field.Name
+	}
+	switch {
+	case strings.Contains(options, "attr"):
+		m.attrs[path+"@"+name] = true
+		return
+	case strings.Contains(options, "innerxml"), strings.Contains(options, "any"):
+		m.opaquePaths = append(m.opaquePaths, path)
+		return
+	case strings.Contains(options, "chardata"), strings.Contains(options, "comment"):
+		return
+	}
+	elementPath := path
+	for _, part := range strings.Split(name, ">") {
+		elementPath += "/" + part
+		m.elements[elementPath] = true
+	}
+	fieldType := field.Type
+	for fieldType.Kind() == reflect.Ptr || (fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() != reflect.Uint8) {
+		fieldType = fieldType.Elem()
+	}
+	m.addStruct(elementPath, fieldType)
+}
+
+func (m *schemaModel) opaque(path string) bool {
+	for _, opaquePath := range m.opaquePaths {
+		if strings.HasPrefix(path, opaquePath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *schemaModel) children(path string) []string {
+	children := []string{}
+	for element := range m.elements {
+		if strings.HasPrefix(element, path+"/") && !strings.Contains(element[len(path)+1:], "/") {
+			children = append(children, element)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
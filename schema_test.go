@@ -0,0 +1,86 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckRoot(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{"tsResponse", `<?xml version="1.0" encoding="UTF-8"?><tsResponse><projects/></tsResponse>`, true},
+		{"namespaced tsResponse", `<tsResponse xmlns="http://tableau.com/api"/>`, true},
+		{"other root", `<html><body>Bad Gateway</body></html>`, false},
+		{"empty", ``, false},
+		{"not xml", `{"projects":[]}`, false},
+	}
+	for _, test := range tests {
+		issue := checkRoot([]byte(test.body))
+		if (issue == nil) != test.ok {
+			t.Errorf("%s: checkRoot returned %v", test.name, issue)
+		}
+		if issue != nil && issue.Kind != SchemaUnexpectedRoot {
+			t.Errorf("%s: issue kind %s, want %s", test.name, issue.Kind, SchemaUnexpectedRoot)
+		}
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	const schemaLocation = `xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://tableau.com/api https://help.tableau.com/samples/en-us/rest_api/ts-api_3_4.xsd"`
+	tests := []struct {
+		name    string
+		body    string
+		version string
+		want    []SchemaIssue
+	}{
+		{
+			name: "matches the model",
+			body: `<tsResponse ` + schemaLocation + `><pagination pageNumber="1" pageSize="100" totalAvailable="1"/>` +
+				`<projects><project id="1" name="Default" parentProjectId="2"/></projects></tsResponse>`,
+			version: "3.4",
+			want:    []SchemaIssue{},
+		},
+		{
+			name:    "newer schema",
+			body:    `<tsResponse ` + schemaLocation + `><pagination/><projects/></tsResponse>`,
+			version: "3.20",
+			want:    []SchemaIssue{{Kind: SchemaVersionMismatch, Path: "tsResponse", Detail: "response uses ts-api 3.4, requested 3.20"}},
+		},
+		{
+			name: "unknown element and attribute are reported once",
+			body: `<tsResponse><pagination/><projects><project id="1" writeable="true"><owner id="2"/></project>` +
+				`<project id="3" writeable="false"><owner id="4"/></project></projects></tsResponse>`,
+			want: []SchemaIssue{
+				{Kind: SchemaUnknownAttribute, Path: "tsResponse/projects/project@writeable"},
+				{Kind: SchemaUnknownElement, Path: "tsResponse/projects/project/owner"},
+			},
+		},
+		{
+			name: "missing top level element",
+			body: `<tsResponse><projects/></tsResponse>`,
+			want: []SchemaIssue{{Kind: SchemaMissingElement, Path: "tsResponse/pagination"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateSchema([]byte(test.body), &QueryProjectsResponse{}, test.version)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("validateSchema returned %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
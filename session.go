@@ -0,0 +1,237 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Tableau Server's default wsgateway.timeout, sessions unused for longer than this are dropped
+const DefaultSessionIdleTimeout = 240 * time.Minute
+
+// how long before the expected expiry the token refresher signs in again
+const DefaultTokenRefreshMargin = 5 * time.Minute
+
+// session is the sign-in state of an API. Copies of an API share it, so one goroutine signing in or
+// re-authenticating is seen by all the others.
+type session struct {
+	mu          sync.RWMutex
+	token       string
+	credentials *Credentials
+	contentUrl  string
+	siteID      string
+	userID      string
+	signedInAt  time.Time
+	lastUsed    time.Time
+	// set once the session held a token, from then on AuthToken is only a mirror of it
+	started bool
+
+	// serializes re-authentication so a burst of expired requests signs in only once
+	reauthMu sync.Mutex
+}
+
+func newSession() *session {
+	return &session{}
+}
+
+// Token returns the token of the current session, or AuthToken when the API wasn't created with NewAPI or
+// hasn't signed in yet. After signing out it's empty.
+func (api *API) Token() string {
+	if api.session == nil {
+		return api.AuthToken
+	}
+	api.session.mu.RLock()
+	defer api.session.mu.RUnlock()
+	if !api.session.started {
+		return api.AuthToken
+	}
+	return api.session.token
+}
+
+// SetToken makes the API use a token obtained elsewhere, e.g. one handed over by another process
+func (api *API) SetToken(token string) {
+	if api.session == nil {
+		api.AuthToken = token
+		return
+	}
+	api.session.mu.Lock()
+	defer api.session.mu.Unlock()
+	// AuthToken is kept in step for callers still reading it, under the lock Token reads it with
+	api.AuthToken = token
+	api.session.token = token
+	api.session.started = true
+}
+
+// records a successful sign-in from the credentials the server returned. replay is nil when the credentials
+// can't be used again, e.g. for a JWT.
+func (api *API) setSignin(signedIn *Credentials, replay *Credentials, contentUrl string) {
+	if api.session == nil {
+		api.AuthToken = signedIn.Token
+		return
+	}
+	api.session.mu.Lock()
+	defer api.session.mu.Unlock()
+	api.AuthToken = signedIn.Token
+	api.session.credentials = replay
+	api.session.setSite(signedIn, contentUrl)
+}
+
+// records a site switch, the stored credentials now sign in to contentUrl
+func (api *API) setSite(signedIn *Credentials, contentUrl string) {
+	if api.session == nil {
+		api.AuthToken = signedIn.Token
+		return
+	}
+	api.session.mu.Lock()
+	defer api.session.mu.Unlock()
+	api.AuthToken = signedIn.Token
+	api.session.setSite(signedIn, contentUrl)
+}
+
+// forgets the session after signing out
+func (api *API) clearSignin() {
+	if api.session == nil {
+		api.AuthToken = ""
+		return
+	}
+	api.session.mu.Lock()
+	defer api.session.mu.Unlock()
+	api.AuthToken = ""
+	api.session.token = ""
+	api.session.credentials = nil
+	api.session.contentUrl = ""
+	api.session.siteID, api.session.userID = "", ""
+	api.session.signedInAt, api.session.lastUsed = time.Time{}, time.Time{}
+}
+
+// callers hold mu
+func (s *session) setSite(signedIn *Credentials, contentUrl string) {
+	s.token = signedIn.Token
+	s.started = true
+	s.signedInAt = time.Now()
+	s.lastUsed = s.signedInAt
+	s.contentUrl = contentUrl
+	s.siteID, s.userID = "", ""
+	if signedIn.Site != nil {
+		s.siteID = signedIn.Site.ID
+	}
+	// the signed in user comes back in the same element impersonation is requested with
+	if signedIn.Impersonate != nil {
+		s.userID = signedIn.Impersonate.ID
+	}
+}
+
+// CurrentSiteID returns the id of the site the session is signed in to, saving a QuerySite call
+func (api *API) CurrentSiteID() string {
+	if api.session == nil {
+		return ""
+	}
+	api.session.mu.RLock()
+	defer api.session.mu.RUnlock()
+	return api.session.siteID
+}
+
+// CurrentUserID returns the id of the signed in (or impersonated) user
+func (api *API) CurrentUserID() string {
+	if api.session == nil {
+		return ""
+	}
+	api.session.mu.RLock()
+	defer api.session.mu.RUnlock()
+	return api.session.userID
+}
+
+func (api *API) signinState() (*Credentials, string) {
+	if api.session == nil {
+		return nil, ""
+	}
+	api.session.mu.RLock()
+	defer api.session.mu.RUnlock()
+	return api.session.credentials, api.session.contentUrl
+}
+
+// every successful request resets the server's idle timer
+func (api *API) touchSession() {
+	if api.session == nil {
+		return
+	}
+	api.session.mu.Lock()
+	defer api.session.mu.Unlock()
+	api.session.lastUsed = time.Now()
+}
+
+// TokenExpiresAt estimates when the server will drop the session, from the last time it was used and the
+// configured SessionIdleTimeout and SessionMaxLifetime. It is the zero time when not signed in.
+func (api *API) TokenExpiresAt() time.Time {
+	if api.session == nil {
+		return time.Time{}
+	}
+	api.session.mu.RLock()
+	defer api.session.mu.RUnlock()
+	if api.session.token == "" {
+		return time.Time{}
+	}
+	idleTimeout := api.SessionIdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultSessionIdleTimeout
+	}
+	expiresAt := api.session.lastUsed.Add(idleTimeout)
+	if api.SessionMaxLifetime > 0 {
+		if absolute := api.session.signedInAt.Add(api.SessionMaxLifetime); absolute.Before(expiresAt) {
+			expiresAt = absolute
+		}
+	}
+	return expiresAt
+}
+
+// TokenValid reports whether the API holds a token the server should still accept
+func (api *API) TokenValid() bool {
+	expiresAt := api.TokenExpiresAt()
+	return !expiresAt.IsZero() && time.Now().Before(expiresAt)
+}
+
+// StartTokenRefresher signs in again in the background whenever the token is within margin of expiring, so
+// long running jobs never see an expired session. Call the returned function to stop it.
+func (api *API) StartTokenRefresher(margin time.Duration) func() {
+	if margin <= 0 {
+		margin = DefaultTokenRefreshMargin
+	}
+	interval := margin / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				expiresAt := api.TokenExpiresAt()
+				if expiresAt.IsZero() || time.Until(expiresAt) > margin {
+					continue
+				}
+				if err := api.reauthenticate(context.Background(), api.Token()); err != nil {
+					api.logger().Errorf("t4g token refresh failed:%v", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
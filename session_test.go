@@ -0,0 +1,64 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/auth/signin"):
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprint(w, `<tsResponse><credentials token="signed-in"><site id="s1" contentUrl=""/><user id="u1"/></credentials></tsResponse>`)
+		case strings.HasSuffix(r.URL.Path, "/auth/signout"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+	api.AuthToken = "preset"
+	if token := api.Token(); token != "preset" {
+		t.Fatalf("Token() before signing in = %q, want the preset AuthToken", token)
+	}
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if api.Token() != "signed-in" || api.AuthToken != "signed-in" {
+		t.Fatalf("after Signin Token() = %q and AuthToken = %q, want both signed-in", api.Token(), api.AuthToken)
+	}
+	if err := api.Signout(); err != nil {
+		t.Fatal(err)
+	}
+	if api.Token() != "" || api.AuthToken != "" {
+		t.Fatalf("after Signout Token() = %q and AuthToken = %q, want both empty", api.Token(), api.AuthToken)
+	}
+	// a stale token written back to the deprecated field isn't sent once the session has signed in
+	api.AuthToken = "preset"
+	if token := api.Token(); token != "" {
+		t.Fatalf("Token() after Signout = %q, want empty", token)
+	}
+
+	api.SetToken("handed-over")
+	if api.Token() != "handed-over" || api.AuthToken != "handed-over" {
+		t.Fatalf("after SetToken Token() = %q and AuthToken = %q", api.Token(), api.AuthToken)
+	}
+}
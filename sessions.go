@@ -0,0 +1,79 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+)
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_server.htm#get-current-server-session
+// returns the site and user of the session the API is signed in with
+func (api *API) GetCurrentSession() (Session, error) {
+	return api.GetCurrentSessionContext(context.Background())
+}
+
+func (api *API) GetCurrentSessionContext(ctx context.Context) (Session, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sessions/current", api.Server, api.Version)
+	headers := make(map[string]string)
+	retval := QuerySessionResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.Session, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_server.htm#list_server_active_sessions
+// requires a server administrator
+func (api *API) QuerySessions() ([]Session, error) {
+	return api.QuerySessionsContext(context.Background())
+}
+
+func (api *API) QuerySessionsContext(ctx context.Context) ([]Session, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sessions", api.Server, api.Version)
+	headers := make(map[string]string)
+	retval := QuerySessionsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.Sessions.Sessions, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_server.htm#delete_server_session
+func (api *API) DeleteSession(sessionId string) error {
+	return api.DeleteSessionContext(context.Background(), sessionId)
+}
+
+func (api *API) DeleteSessionContext(ctx context.Context, sessionId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sessions/%s", api.Server, api.Version, sessionId)
+	return api.delete(ctx, requestUrl)
+}
+
+// signs the user out everywhere by deleting each of their active sessions, returns the number of sessions deleted
+func (api *API) DeleteUserSessions(userId string) (int, error) {
+	return api.DeleteUserSessionsContext(context.Background(), userId)
+}
+
+func (api *API) DeleteUserSessionsContext(ctx context.Context, userId string) (int, error) {
+	sessions, err := api.QuerySessionsContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, session := range sessions {
+		if session.User == nil || session.User.ID != userId {
+			continue
+		}
+		if err = api.DeleteSessionContext(ctx, session.ID); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	api.loggerFor(ctx).Infof("Deleted %d sessions for userId %s", deleted, userId)
+	return deleted, nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#create_site
+// needs a server administrator session, e.g. CreateSite(SiteRequest{Name: "Tenant", ContentUrl: "tenant", UserQuota: Int(50)})
+func (api *API) CreateSite(site SiteRequest) (Site, error) {
+	return api.CreateSiteContext(context.Background(), site)
+}
+
+func (api *API) CreateSiteContext(ctx context.Context, site SiteRequest) (Site, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites", api.Server, api.Version)
+	payload, headers, err := api.encodeRequest(CreateSiteRequest{Request: site})
+	if err != nil {
+		return Site{}, err
+	}
+	retval := QuerySiteResponse{}
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	return retval.Site, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#update_site
+// PUT /api/api-version/sites/site-id, e.g. UpdateSite(siteId, SiteUpdate{UserQuota: Int(100)})
+func (api *API) UpdateSite(siteId string, update SiteUpdate) (Site, error) {
+	return api.UpdateSiteContext(context.Background(), siteId, update)
+}
+
+func (api *API) UpdateSiteContext(ctx context.Context, siteId string, update SiteUpdate) (Site, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, siteId)
+	payload, headers, err := api.encodeRequest(UpdateSiteRequest{Request: update})
+	if err != nil {
+		return Site{}, err
+	}
+	retval := QuerySiteResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.Site, err
+}
+
+// turns revision history on or off for the site. revisionLimit is the number of revisions kept per workbook and
+// datasource, -1 lets the server keep an unlimited number and 0 leaves the current limit untouched.
+func (api *API) SetRevisionHistory(siteId string, enabled bool, revisionLimit int) (Site, error) {
+	return api.SetRevisionHistoryContext(context.Background(), siteId, enabled, revisionLimit)
+}
+
+func (api *API) SetRevisionHistoryContext(ctx context.Context, siteId string, enabled bool, revisionLimit int) (Site, error) {
+	update := SiteUpdate{RevisionHistoryEnabled: Bool(enabled)}
+	if enabled && revisionLimit != 0 {
+		update.RevisionLimit = Int(revisionLimit)
+	}
+	return api.UpdateSiteContext(ctx, siteId, update)
+}
+
+// turns webhooks on or off for the site
+func (api *API) SetWebhooksEnabled(siteId string, enabled bool) (Site, error) {
+	return api.SetWebhooksEnabledContext(context.Background(), siteId, enabled)
+}
+
+func (api *API) SetWebhooksEnabledContext(ctx context.Context, siteId string, enabled bool) (Site, error) {
+	return api.UpdateSiteContext(ctx, siteId, SiteUpdate{WebhooksEnabled: Bool(enabled)})
+}
+
+// when enabled, users without access to content can request it from the content owner or project leader
+func (api *API) SetRequestAccessEnabled(siteId string, enabled bool) (Site, error) {
+	return api.SetRequestAccessEnabledContext(context.Background(), siteId, enabled)
+}
+
+func (api *API) SetRequestAccessEnabledContext(ctx context.Context, siteId string, enabled bool) (Site, error) {
+	return api.UpdateSiteContext(ctx, siteId, SiteUpdate{RequestAccessEnabled: Bool(enabled)})
+}
+
+// ExtractEncryptionMode is whether the extracts of a site are encrypted at rest
+// https://help.tableau.com/current/server/en-us/security_ear.htm
+type ExtractEncryptionMode string
+
+const ExtractEncryptionDisabled ExtractEncryptionMode = "disabled"
+const ExtractEncryptionEnabled ExtractEncryptionMode = "enabled"
+const ExtractEncryptionEnforced ExtractEncryptionMode = "enforced"
+
+// requiring extract encryption sets the site's extractEncryptionMode to "enforced", otherwise encryption is
+// left to the publisher ("enabled")
+func (api *API) SetExtractEncryptionRequired(siteId string, required bool) (Site, error) {
+	return api.SetExtractEncryptionRequiredContext(context.Background(), siteId, required)
+}
+
+func (api *API) SetExtractEncryptionRequiredContext(ctx context.Context, siteId string, required bool) (Site, error) {
+	mode := ExtractEncryptionEnabled
+	if required {
+		mode = ExtractEncryptionEnforced
+	}
+	return api.SetExtractEncryptionModeContext(ctx, siteId, mode)
+}
+
+func (api *API) GetExtractEncryptionMode(siteId string) (ExtractEncryptionMode, error) {
+	return api.GetExtractEncryptionModeContext(context.Background(), siteId)
+}
+
+func (api *API) GetExtractEncryptionModeContext(ctx context.Context, siteId string) (ExtractEncryptionMode, error) {
+	site, err := api.QuerySiteContext(ctx, siteId, false)
+	return ExtractEncryptionMode(site.ExtractEncryptionMode), err
+}
+
+// one of ExtractEncryptionDisabled, ExtractEncryptionEnabled or ExtractEncryptionEnforced. Changing the mode
+// doesn't touch existing extracts, see EncryptExtracts and DecryptExtracts.
+func (api *API) SetExtractEncryptionMode(siteId string, mode ExtractEncryptionMode) (Site, error) {
+	return api.SetExtractEncryptionModeContext(context.Background(), siteId, mode)
+}
+
+func (api *API) SetExtractEncryptionModeContext(ctx context.Context, siteId string, mode ExtractEncryptionMode) (Site, error) {
+	switch mode {
+	case ExtractEncryptionDisabled, ExtractEncryptionEnabled, ExtractEncryptionEnforced:
+	default:
+		return Site{}, fmt.Errorf("unknown extract encryption mode '%s'", mode)
+	}
+	return api.UpdateSiteContext(ctx, siteId, SiteUpdate{ExtractEncryptionMode: String(string(mode))})
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_extract_and_encryption.htm#encrypt_extracts
+// encrypts every extract of the site in a background job
+func (api *API) EncryptExtracts(siteId string) error {
+	return api.EncryptExtractsContext(context.Background(), siteId)
+}
+
+func (api *API) EncryptExtractsContext(ctx context.Context, siteId string) error {
+	return api.extractEncryptionAction(ctx, siteId, "encrypt-extracts")
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_extract_and_encryption.htm#decrypt_extracts
+func (api *API) DecryptExtracts(siteId string) error {
+	return api.DecryptExtractsContext(context.Background(), siteId)
+}
+
+func (api *API) DecryptExtractsContext(ctx context.Context, siteId string) error {
+	return api.extractEncryptionAction(ctx, siteId, "decrypt-extracts")
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_extract_and_encryption.htm#reencrypt_extracts
+// encrypts the extracts again with new keys, e.g. after the keys may have been exposed
+func (api *API) ReencryptExtracts(siteId string) error {
+	return api.ReencryptExtractsContext(context.Background(), siteId)
+}
+
+func (api *API) ReencryptExtractsContext(ctx context.Context, siteId string) error {
+	return api.extractEncryptionAction(ctx, siteId, "reencrypt-extracts")
+}
+
+func (api *API) extractEncryptionAction(ctx context.Context, siteId, action string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/%s", api.Server, api.Version, siteId, action)
+	headers := make(map[string]string)
+	return api.makeRequest(ctx, requestUrl, POST, nil, nil, headers)
+}
+
+// the server models subscriptions as disableSubscriptions, this flips it so callers don't have to
+func (api *API) SetSubscriptionsEnabled(siteId string, enabled bool) (Site, error) {
+	return api.SetSubscriptionsEnabledContext(context.Background(), siteId, enabled)
+}
+
+func (api *API) SetSubscriptionsEnabledContext(ctx context.Context, siteId string, enabled bool) (Site, error) {
+	return api.UpdateSiteContext(ctx, siteId, SiteUpdate{DisableSubscriptions: Bool(!enabled)})
+}
+
+// renaming a site changes the urls of its content, signing in to it needs the new content url
+func (api *API) RenameSite(siteId, name, contentUrl string) (Site, error) {
+	return api.RenameSiteContext(context.Background(), siteId, name, contentUrl)
+}
+
+func (api *API) RenameSiteContext(ctx context.Context, siteId, name, contentUrl string) (Site, error) {
+	return api.UpdateSiteContext(ctx, siteId, SiteUpdate{Name: String(name), ContentUrl: String(contentUrl)})
+}
+
+// ErrSuspendCurrentSite is returned when asked to suspend the site the session is signed in to, which would
+// lock the session out of it
+var ErrSuspendCurrentSite = errors.New("refusing to suspend the site the session is signed in to")
+
+// suspended sites keep their content but nobody other than server administrators can sign in to them
+func (api *API) SetSiteState(siteId string, state SiteState) (Site, error) {
+	return api.SetSiteStateContext(context.Background(), siteId, state)
+}
+
+func (api *API) SetSiteStateContext(ctx context.Context, siteId string, state SiteState) (Site, error) {
+	switch state {
+	case SiteStateActive:
+	case SiteStateSuspended:
+		currentSiteId, err := api.currentSiteIDContext(ctx)
+		if err != nil {
+			return Site{}, err
+		}
+		if siteId == currentSiteId {
+			return Site{}, ErrSuspendCurrentSite
+		}
+	default:
+		return Site{}, fmt.Errorf("unknown site state '%s'", state)
+	}
+	return api.UpdateSiteContext(ctx, siteId, SiteUpdate{State: String(string(state))})
+}
+
+// the site the session is signed in to, asking the server when the session doesn't know it, e.g. for a token
+// handed over with SetToken
+func (api *API) currentSiteIDContext(ctx context.Context) (string, error) {
+	if siteId := api.CurrentSiteID(); siteId != "" {
+		return siteId, nil
+	}
+	session, err := api.GetCurrentSessionContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("can't tell which site the session is signed in to: %w", err)
+	}
+	if session.Site == nil || session.Site.ID == "" {
+		return "", errors.New("can't tell which site the session is signed in to")
+	}
+	return session.Site.ID, nil
+}
+
+func (api *API) SetSiteSuspended(siteId string, suspended bool) (Site, error) {
+	return api.SetSiteSuspendedContext(context.Background(), siteId, suspended)
+}
+
+func (api *API) SetSiteSuspendedContext(ctx context.Context, siteId string, suspended bool) (Site, error) {
+	state := SiteStateActive
+	if suspended {
+		state = SiteStateSuspended
+	}
+	return api.SetSiteStateContext(ctx, siteId, state)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#list_authentication_configurations_site
+// lists the authentication types users of the site can be assigned, see UpdateUserAuthentication
+func (api *API) QuerySiteAuthConfigurations(siteId string) ([]SiteAuthConfiguration, error) {
+	return api.QuerySiteAuthConfigurationsContext(context.Background(), siteId)
+}
+
+func (api *API) QuerySiteAuthConfigurationsContext(ctx context.Context, siteId string) ([]SiteAuthConfiguration, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/site-auth-configurations", api.Server, api.Version, siteId)
+	headers := make(map[string]string)
+	retval := QuerySiteAuthConfigurationsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.SiteAuthConfigurations.SiteAuthConfigurations, err
+}
@@ -0,0 +1,70 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// a token handed over with SetToken doesn't name its site, suspending asks the server which one it is
+func TestSetSiteStateUnknownCurrentSite(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		siteId  string
+		updated bool
+		want    error
+	}{
+		{"other site", `<session id="s"><site id="current"/></session>`, "other", true, nil},
+		{"current site", `<session id="s"><site id="current"/></session>`, "current", false, ErrSuspendCurrentSite},
+		{"session without a site", `<session id="s"/>`, "other", false, nil},
+		{"session lookup fails", "", "other", false, ErrNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updated := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPut:
+					updated = true
+					w.Header().Set("Content-Type", "application/xml")
+					fmt.Fprint(w, `<tsResponse><site id="other" state="Suspended"/></tsResponse>`)
+				case strings.HasSuffix(r.URL.Path, "/sessions/current") && test.session != "":
+					w.Header().Set("Content-Type", "application/xml")
+					fmt.Fprintf(w, "<tsResponse>%s</tsResponse>", test.session)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			api := NewAPI(server.URL, "3.4", BoundaryString, "", true, time.Second, time.Second)
+			api.SetToken("handed-over")
+
+			_, err := api.SetSiteState(test.siteId, SiteStateSuspended)
+			if updated != test.updated {
+				t.Fatalf("site updated = %v, want %v (err %v)", updated, test.updated, err)
+			}
+			if test.updated && err != nil {
+				t.Fatal(err)
+			}
+			if !test.updated && (err == nil || (test.want != nil && !errors.Is(err, test.want))) {
+				t.Fatalf("SetSiteState returned %v, want %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// SiteUsageReport sums up how much of its capacity a site uses, storage is in megabytes and a zero quota means
+// the site has none
+type SiteUsageReport struct {
+	SiteID         string
+	Name           string
+	ContentUrl     string
+	StorageUsedMB  int
+	StorageQuotaMB int
+	Users          int
+	UserQuota      int
+	Projects       int
+	Workbooks      int
+	Datasources    int
+	Views          int
+}
+
+// the fraction of the storage quota in use, 0 when the site has no quota
+func (r SiteUsageReport) StorageUsed() float64 {
+	if r.StorageQuotaMB <= 0 {
+		return 0
+	}
+	return float64(r.StorageUsedMB) / float64(r.StorageQuotaMB)
+}
+
+// queries the site with its storage and counts its content, one request per content type
+func (api *API) GetSiteUsage(siteId string) (SiteUsageReport, error) {
+	return api.GetSiteUsageContext(context.Background(), siteId)
+}
+
+func (api *API) GetSiteUsageContext(ctx context.Context, siteId string) (SiteUsageReport, error) {
+	site, err := api.QuerySiteContext(ctx, siteId, true)
+	if err != nil {
+		return SiteUsageReport{}, err
+	}
+	report := SiteUsageReport{SiteID: site.ID, Name: site.Name, ContentUrl: site.ContentUrl, StorageQuotaMB: site.StorageQuota}
+	report.UserQuota, _ = strconv.Atoi(site.UserQuota)
+	if site.Usage != nil {
+		report.StorageUsedMB = site.Usage.Storage
+		report.Users = site.Usage.NumberOfUsers
+	}
+	counts := []struct {
+		content string
+		count   *int
+	}{
+		{"projects", &report.Projects},
+		{"workbooks", &report.Workbooks},
+		{"datasources", &report.Datasources},
+		{"views", &report.Views},
+	}
+	for _, c := range counts {
+		if *c.count, err = api.countContent(ctx, siteId, c.content); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+type paginationResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+}
+
+// asks for a single item, the pagination tells how many there are
+func (api *API) countContent(ctx context.Context, siteId, content string) (int, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/%s", api.Server, api.Version, siteId, content), 1, []QueryOption{WithPageSize(1)})
+	headers := make(map[string]string)
+	retval := paginationResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.Pagination.TotalAvailable, err
+}
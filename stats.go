@@ -0,0 +1,103 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const retryAfterHeader = "Retry-After"
+const rateLimitLimitHeader = "X-RateLimit-Limit"
+const rateLimitRemainingHeader = "X-RateLimit-Remaining"
+const rateLimitResetHeader = "X-RateLimit-Reset"
+
+// Stats is a snapshot of the requests made by an API and the rate limits the server reported for them.
+// The rate limit fields are -1 until the server sends the corresponding header.
+type Stats struct {
+	Requests  int64
+	Throttled int64
+	// requests sent again by the RetryPolicy
+	Retries int64
+	// the rate limit headers of the most recent response that had them
+	RateLimitLimit     int
+	RateLimitRemaining int
+	RateLimitReset     time.Time
+	// the wait the server asked for on the most recent 429
+	RetryAfter      time.Duration
+	LastThrottledAt time.Time
+}
+
+type clientStats struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+func newClientStats() *clientStats {
+	return &clientStats{stats: Stats{RateLimitLimit: -1, RateLimitRemaining: -1}}
+}
+
+func (c *clientStats) record(resp *http.Response, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Requests++
+	if limit, err := strconv.Atoi(resp.Header.Get(rateLimitLimitHeader)); err == nil {
+		c.stats.RateLimitLimit = limit
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get(rateLimitRemainingHeader)); err == nil {
+		c.stats.RateLimitRemaining = remaining
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get(rateLimitResetHeader), 10, 64); err == nil {
+		c.stats.RateLimitReset = time.Unix(reset, 0)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.stats.Throttled++
+		c.stats.LastThrottledAt = now
+		c.stats.RetryAfter, _ = parseRetryAfter(resp.Header.Get(retryAfterHeader), now)
+	}
+}
+
+func (c *clientStats) retried() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Retries++
+}
+
+// Stats returns the request and rate limit counters of this API, batch jobs can use them to adapt their
+// concurrency. APIs not created with NewAPI don't collect stats.
+func (api *API) Stats() Stats {
+	if api.stats == nil {
+		return Stats{RateLimitLimit: -1, RateLimitRemaining: -1}
+	}
+	api.stats.mu.Lock()
+	defer api.stats.mu.Unlock()
+	return api.stats.stats
+}
+
+// Retry-After is either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,599 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tableau4gotest provides an in-memory fake of the Tableau REST API for integration style tests of
+// code built on tableau4go. It implements signing in and out, server info, sites, projects, and querying,
+// publishing, downloading and deleting datasources.
+//
+//	server := tableau4gotest.NewServer()
+//	defer server.Close()
+//	api := server.API()
+//	err := api.Signin("admin", "secret", "", "")
+package tableau4gotest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AtScaleInc/tableau4go"
+)
+
+// the REST API version the fake server advertises
+const APIVersion = "3.4"
+
+// the site every server starts with, signed in to with an empty content url
+const DefaultSiteName = "Default"
+
+type site struct {
+	tableau4go.Site
+	projects    []tableau4go.Project
+	datasources []*datasource
+}
+
+type datasource struct {
+	tableau4go.Datasource
+	content []byte
+}
+
+// Server is a fake Tableau Server, its state lives in memory and is lost on Close
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	nextID      int
+	users       map[string]string
+	userIDs     map[string]string
+	sites       []*site
+	tokens      map[string]string
+	fileUploads map[string][]byte
+}
+
+// NewServer starts a fake server with the default site and its "Default" project. Until AddUser is called
+// any user name and password signs in.
+func NewServer() *Server {
+	s := &Server{
+		users:       map[string]string{},
+		userIDs:     map[string]string{},
+		tokens:      map[string]string{},
+		fileUploads: map[string][]byte{},
+	}
+	defaultSite := s.AddSite(DefaultSiteName, "")
+	s.AddProject(defaultSite.ID, "Default")
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// API returns a client for the server
+func (s *Server) API() tableau4go.API {
+	return tableau4go.NewAPI(s.URL, APIVersion, tableau4go.BoundaryString, DefaultSiteName, true, 5*time.Second, 30*time.Second)
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", s.nextID)
+}
+
+// AddUser restricts signing in to the users added
+func (s *Server) AddUser(name, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[name] = password
+}
+
+// AddSite creates a site and returns it
+func (s *Server) AddSite(name, contentUrl string) tableau4go.Site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	created := &site{Site: tableau4go.Site{ID: s.newID(), Name: name, ContentUrl: contentUrl, State: "Active"}}
+	s.sites = append(s.sites, created)
+	return created.Site
+}
+
+// AddProject creates a project on the site with the given id and returns it
+func (s *Server) AddProject(siteId, name string) tableau4go.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	project := tableau4go.Project{ID: s.newID(), Name: name}
+	if site := s.siteByID(siteId); site != nil {
+		site.projects = append(site.projects, project)
+	}
+	return project
+}
+
+// DatasourceContent returns the file last published for a datasource
+func (s *Server) DatasourceContent(siteId, datasourceId string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if site := s.siteByID(siteId); site != nil {
+		for _, ds := range site.datasources {
+			if ds.ID == datasourceId {
+				return ds.content, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) siteByID(id string) *site {
+	for _, site := range s.sites {
+		if site.ID == id {
+			return site
+		}
+	}
+	return nil
+}
+
+func (s *Server) siteBy(key, value string) *site {
+	for _, site := range s.sites {
+		if (key == "name" && site.Name == value) || (key == "contentUrl" && site.ContentUrl == value) || (key == "" && site.ID == value) {
+			return site
+		}
+	}
+	return nil
+}
+
+type tsError struct {
+	Code    string `xml:"code,attr"`
+	Summary string `xml:"summary"`
+	Detail  string `xml:"detail"`
+}
+
+// the responses have their root element renamed to tsResponse by writeResponse
+type errorResponse struct {
+	Error tsError `xml:"error"`
+}
+
+type authResponse struct {
+	Credentials tableau4go.Credentials `xml:"credentials"`
+}
+
+type serverInfoResponse struct {
+	ServerInfo struct {
+		ProductVersion string `xml:"productVersion"`
+		RestApiVersion string `xml:"restApiVersion"`
+	} `xml:"serverInfo"`
+}
+
+type sitesResponse struct {
+	Pagination tableau4go.Pagination `xml:"pagination"`
+	Sites      []tableau4go.Site     `xml:"sites>site"`
+}
+
+type siteResponse struct {
+	Site tableau4go.Site `xml:"site"`
+}
+
+type projectsResponse struct {
+	Pagination tableau4go.Pagination `xml:"pagination"`
+	Projects   []tableau4go.Project  `xml:"projects>project"`
+}
+
+type projectResponse struct {
+	Project tableau4go.Project `xml:"project"`
+}
+
+type datasourcesResponse struct {
+	Pagination  tableau4go.Pagination   `xml:"pagination"`
+	Datasources []tableau4go.Datasource `xml:"datasources>datasource"`
+}
+
+type datasourceResponse struct {
+	Datasource tableau4go.Datasource `xml:"datasource"`
+}
+
+type fileUploadResponse struct {
+	FileUpload tableau4go.FileUpload `xml:"fileUpload"`
+}
+
+func writeResponse(w http.ResponseWriter, status int, response interface{}) {
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	if err := xml.NewEncoder(&body).EncodeElement(response, xml.StartElement{Name: xml.Name{Local: "tsResponse"}}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
+}
+
+func writeError(w http.ResponseWriter, status int, code, summary, detail string) {
+	writeResponse(w, status, errorResponse{Error: tsError{Code: code, Summary: summary, Detail: detail}})
+}
+
+func readRequest(r *http.Request, request interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(body, request)
+}
+
+var apiPath = regexp.MustCompile(`^/api/[^/]+/`)
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !apiPath.MatchString(r.URL.Path) {
+		writeError(w, http.StatusNotFound, "404000", "Resource Not Found", r.URL.Path)
+		return
+	}
+	// split before unescaping, names looked up by key may hold slashes
+	path := strings.Split(strings.Trim(apiPath.ReplaceAllString(r.URL.EscapedPath(), ""), "/"), "/")
+	for i, segment := range path {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			path[i] = unescaped
+		}
+	}
+	switch {
+	case r.Method == http.MethodPost && len(path) == 2 && path[0] == "auth" && path[1] == "signin":
+		s.signin(w, r)
+		return
+	case r.Method == http.MethodGet && len(path) == 1 && path[0] == "serverinfo":
+		response := serverInfoResponse{}
+		response.ServerInfo.ProductVersion = "2023.1.0"
+		response.ServerInfo.RestApiVersion = APIVersion
+		writeResponse(w, http.StatusOK, response)
+		return
+	}
+	siteId, ok := s.tokens[r.Header.Get("X-Tableau-Auth")]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "401002", "Unauthorized Access", "Invalid authentication credentials were provided.")
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && len(path) == 2 && path[0] == "auth" && path[1] == "signout":
+		delete(s.tokens, r.Header.Get("X-Tableau-Auth"))
+		w.WriteHeader(http.StatusNoContent)
+	case path[0] == "sites" && len(path) <= 2:
+		s.serveSites(w, r, path)
+	case len(path) < 3 || path[0] != "sites":
+		writeError(w, http.StatusNotFound, "404000", "Resource Not Found", r.URL.Path)
+	case path[1] != siteId:
+		writeError(w, http.StatusForbidden, "403000", "Forbidden", "The session is signed in to another site.")
+	case path[2] == "projects":
+		s.serveProjects(w, r, s.siteByID(siteId), path[3:])
+	case path[2] == "datasources":
+		s.serveDatasources(w, r, s.siteByID(siteId), path[3:])
+	case path[2] == "fileUploads":
+		s.serveFileUploads(w, r, path[3:])
+	default:
+		writeError(w, http.StatusNotFound, "404000", "Resource Not Found", r.URL.Path)
+	}
+}
+
+func (s *Server) signin(w http.ResponseWriter, r *http.Request) {
+	request := struct {
+		Credentials tableau4go.Credentials `xml:"credentials"`
+	}{}
+	if err := readRequest(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "400000", "Bad Request", err.Error())
+		return
+	}
+	credentials := request.Credentials
+	if password, ok := s.users[credentials.Name]; len(s.users) > 0 && (!ok || password != credentials.Password) {
+		writeError(w, http.StatusUnauthorized, "401001", "Signin Error", "Error signing in to Tableau Server")
+		return
+	}
+	contentUrl := ""
+	if credentials.Site != nil {
+		contentUrl = credentials.Site.ContentUrl
+	}
+	site := s.siteBy("contentUrl", contentUrl)
+	if site == nil {
+		writeError(w, http.StatusUnauthorized, "401001", "Signin Error", "Site not found")
+		return
+	}
+	userId, ok := s.userIDs[credentials.Name]
+	if !ok {
+		userId = s.newID()
+		s.userIDs[credentials.Name] = userId
+	}
+	token := s.newID()
+	s.tokens[token] = site.ID
+	writeResponse(w, http.StatusOK, authResponse{Credentials: tableau4go.Credentials{
+		Token: token,
+		Site:  &tableau4go.Site{ID: site.ID, ContentUrl: site.ContentUrl},
+		// the user is returned where Credentials keeps the user to impersonate
+		Impersonate: &tableau4go.User{ID: userId},
+	}})
+}
+
+func (s *Server) serveSites(w http.ResponseWriter, r *http.Request, path []string) {
+	switch {
+	case r.Method == http.MethodGet && len(path) == 1:
+		sites := []tableau4go.Site{}
+		for _, site := range s.sites {
+			sites = append(sites, site.Site)
+		}
+		writeResponse(w, http.StatusOK, sitesResponse{Pagination: tableau4go.Pagination{PageNumber: 1, PageSize: len(sites), TotalAvailable: len(sites)}, Sites: sites})
+	case r.Method == http.MethodPost && len(path) == 1:
+		request := struct {
+			Site tableau4go.Site `xml:"site"`
+		}{}
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, http.StatusBadRequest, "400000", "Bad Request", err.Error())
+			return
+		}
+		if s.siteBy("contentUrl", request.Site.ContentUrl) != nil {
+			writeError(w, http.StatusConflict, "409001", "Conflict", "A site with this content url already exists.")
+			return
+		}
+		created := &site{Site: request.Site}
+		created.ID, created.State = s.newID(), "Active"
+		s.sites = append(s.sites, created)
+		writeResponse(w, http.StatusCreated, siteResponse{Site: created.Site})
+	case r.Method == http.MethodGet:
+		site := s.siteBy(r.URL.Query().Get("key"), path[1])
+		if site == nil {
+			writeError(w, http.StatusNotFound, "404000", "Site Not Found", path[1])
+			return
+		}
+		writeResponse(w, http.StatusOK, siteResponse{Site: site.Site})
+	case r.Method == http.MethodDelete:
+		for i, site := range s.sites {
+			if (r.URL.Query().Get("key") == "" && site.ID == path[1]) || s.siteBy(r.URL.Query().Get("key"), path[1]) == site {
+				s.sites = append(s.sites[:i], s.sites[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "404000", "Site Not Found", path[1])
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "405000", "Method Not Allowed", r.Method)
+	}
+}
+
+// the page the pageSize and pageNumber parameters select, and its pagination
+func page(r *http.Request, total int) (int, int, tableau4go.Pagination) {
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 100
+	}
+	pageNumber, err := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+	if err != nil || pageNumber <= 0 {
+		pageNumber = 1
+	}
+	start := (pageNumber - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end, tableau4go.Pagination{PageNumber: pageNumber, PageSize: pageSize, TotalAvailable: total}
+}
+
+// only name:eq:value filters are supported
+func nameFilter(r *http.Request) (string, bool) {
+	filter := r.URL.Query().Get("filter")
+	if !strings.HasPrefix(filter, "name:eq:") {
+		return "", false
+	}
+	return strings.TrimPrefix(filter, "name:eq:"), true
+}
+
+func (s *Server) serveProjects(w http.ResponseWriter, r *http.Request, site *site, path []string) {
+	switch {
+	case r.Method == http.MethodGet && len(path) == 0:
+		projects := []tableau4go.Project{}
+		name, filtered := nameFilter(r)
+		for _, project := range site.projects {
+			if !filtered || project.Name == name {
+				projects = append(projects, project)
+			}
+		}
+		start, end, pagination := page(r, len(projects))
+		writeResponse(w, http.StatusOK, projectsResponse{Pagination: pagination, Projects: projects[start:end]})
+	case r.Method == http.MethodPost && len(path) == 0:
+		request := struct {
+			Project tableau4go.Project `xml:"project"`
+		}{}
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, http.StatusBadRequest, "400000", "Bad Request", err.Error())
+			return
+		}
+		for _, project := range site.projects {
+			if project.Name == request.Project.Name && project.ParentProjectID == request.Project.ParentProjectID {
+				writeError(w, http.StatusConflict, "409006", "Resource Conflict", "A project with this name already exists.")
+				return
+			}
+		}
+		project := request.Project
+		project.ID = s.newID()
+		site.projects = append(site.projects, project)
+		writeResponse(w, http.StatusCreated, projectResponse{Project: project})
+	case r.Method == http.MethodPut && len(path) == 1:
+		request := struct {
+			Project tableau4go.ProjectUpdate `xml:"project"`
+		}{}
+		if err := readRequest(r, &request); err != nil {
+			writeError(w, http.StatusBadRequest, "400000", "Bad Request", err.Error())
+			return
+		}
+		for i, project := range site.projects {
+			if project.ID != path[0] {
+				continue
+			}
+			if request.Project.Name != nil {
+				project.Name = *request.Project.Name
+			}
+			if request.Project.Description != nil {
+				project.Description = *request.Project.Description
+			}
+			if request.Project.ContentPermissions != nil {
+				project.ContentPermissions = *request.Project.ContentPermissions
+			}
+			if request.Project.ParentProjectID != nil {
+				project.ParentProjectID = *request.Project.ParentProjectID
+			}
+			site.projects[i] = project
+			writeResponse(w, http.StatusOK, projectResponse{Project: project})
+			return
+		}
+		writeError(w, http.StatusNotFound, "404005", "Project Not Found", path[0])
+	case r.Method == http.MethodDelete && len(path) == 1:
+		for i, project := range site.projects {
+			if project.ID == path[0] {
+				site.projects = append(site.projects[:i], site.projects[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "404005", "Project Not Found", path[0])
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "405000", "Method Not Allowed", r.Method)
+	}
+}
+
+func (s *Server) serveDatasources(w http.ResponseWriter, r *http.Request, site *site, path []string) {
+	switch {
+	case r.Method == http.MethodGet && len(path) == 0:
+		datasources := []tableau4go.Datasource{}
+		name, filtered := nameFilter(r)
+		for _, ds := range site.datasources {
+			if !filtered || ds.Name == name {
+				datasources = append(datasources, ds.Datasource)
+			}
+		}
+		start, end, pagination := page(r, len(datasources))
+		writeResponse(w, http.StatusOK, datasourcesResponse{Pagination: pagination, Datasources: datasources[start:end]})
+	case r.Method == http.MethodPost && len(path) == 0:
+		s.publishDatasource(w, r, site)
+	case r.Method == http.MethodGet && len(path) == 2 && path[1] == "content":
+		for _, ds := range site.datasources {
+			if ds.ID == path[0] {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Write(ds.content)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "404004", "Datasource Not Found", path[0])
+	case r.Method == http.MethodDelete && len(path) == 1:
+		for i, ds := range site.datasources {
+			if ds.ID == path[0] {
+				site.datasources = append(site.datasources[:i], site.datasources[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "404004", "Datasource Not Found", path[0])
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "405000", "Method Not Allowed", r.Method)
+	}
+}
+
+var partName = regexp.MustCompile(`name="([^"]*)"`)
+
+// the parts of a multipart/mixed publish request by their name
+func readParts(r *http.Request) (map[string][]byte, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	parts := map[string][]byte{}
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if err.Error() == "EOF" {
+				return parts, nil
+			}
+			return nil, err
+		}
+		match := partName.FindStringSubmatch(part.Header.Get("Content-Disposition"))
+		if match == nil {
+			return nil, fmt.Errorf("part without a name")
+		}
+		if parts[match[1]], err = ioutil.ReadAll(part); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *Server) publishDatasource(w http.ResponseWriter, r *http.Request, site *site) {
+	parts, err := readParts(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400000", "Bad Request", err.Error())
+		return
+	}
+	request := struct {
+		Datasource tableau4go.Datasource `xml:"datasource"`
+	}{}
+	if err = xml.Unmarshal(parts["request_payload"], &request); err != nil {
+		writeError(w, http.StatusBadRequest, "400000", "Bad Request", err.Error())
+		return
+	}
+	content, ok := parts["tableau_datasource"]
+	if uploadSessionId := r.URL.Query().Get("uploadSessionId"); uploadSessionId != "" {
+		content, ok = s.fileUploads[uploadSessionId]
+		delete(s.fileUploads, uploadSessionId)
+	}
+	if !ok {
+		writeError(w, http.StatusBadRequest, "400000", "Bad Request", "The datasource file is missing.")
+		return
+	}
+	published := &datasource{Datasource: request.Datasource, content: content}
+	published.Type = r.URL.Query().Get("datasourceType")
+	for i, ds := range site.datasources {
+		if ds.Name != published.Name {
+			continue
+		}
+		if r.URL.Query().Get("overwrite") != "true" {
+			writeError(w, http.StatusConflict, "409005", "Resource Conflict", "A datasource with this name already exists.")
+			return
+		}
+		published.ID = ds.ID
+		site.datasources[i] = published
+		writeResponse(w, http.StatusCreated, datasourceResponse{Datasource: published.Datasource})
+		return
+	}
+	published.ID = s.newID()
+	site.datasources = append(site.datasources, published)
+	writeResponse(w, http.StatusCreated, datasourceResponse{Datasource: published.Datasource})
+}
+
+func (s *Server) serveFileUploads(w http.ResponseWriter, r *http.Request, path []string) {
+	switch {
+	case r.Method == http.MethodPost && len(path) == 0:
+		uploadSessionId := s.newID()
+		s.fileUploads[uploadSessionId] = []byte{}
+		writeResponse(w, http.StatusCreated, fileUploadResponse{FileUpload: tableau4go.FileUpload{UploadSessionID: uploadSessionId}})
+	case r.Method == http.MethodPut && len(path) == 1:
+		content, ok := s.fileUploads[path[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "404000", "Upload Session Not Found", path[0])
+			return
+		}
+		parts, err := readParts(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "400000", "Bad Request", err.Error())
+			return
+		}
+		s.fileUploads[path[0]] = append(content, parts["tableau_file"]...)
+		writeResponse(w, http.StatusOK, fileUploadResponse{FileUpload: tableau4go.FileUpload{UploadSessionID: path[0], FileSize: int64(len(s.fileUploads[path[0]]))}})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "405000", "Method Not Allowed", r.Method)
+	}
+}
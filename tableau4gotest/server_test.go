@@ -0,0 +1,106 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4gotest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AtScaleInc/tableau4go"
+)
+
+func signedIn(t *testing.T) (*Server, tableau4go.API) {
+	t.Helper()
+	server := NewServer()
+	t.Cleanup(server.Close)
+	server.AddUser("admin", "secret")
+	api := server.API()
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	return server, api
+}
+
+func TestSigninRejectsUnknownUser(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.AddUser("admin", "secret")
+	api := server.API()
+	if err := api.Signin("admin", "wrong", "", ""); err == nil {
+		t.Fatal("Signin with a wrong password succeeded")
+	}
+}
+
+func TestQueryProjects(t *testing.T) {
+	server, api := signedIn(t)
+	siteId := api.CurrentSiteID()
+	server.AddProject(siteId, "Finance")
+
+	projects, err := api.QueryProjects(siteId)
+	if err != nil {
+		t.Fatalf("QueryProjects: %v", err)
+	}
+	names := map[string]bool{}
+	for _, project := range projects {
+		names[project.Name] = true
+	}
+	if len(projects) != 2 || !names["Default"] || !names["Finance"] {
+		t.Fatalf("QueryProjects returned %+v, want Default and Finance", projects)
+	}
+}
+
+func TestPublishDatasourceFrom(t *testing.T) {
+	tests := []struct {
+		name string
+		size func(content []byte) int64
+	}{
+		{name: "known size", size: func(content []byte) int64 { return int64(len(content)) }},
+		{name: "read to EOF", size: func([]byte) int64 { return -1 }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, api := signedIn(t)
+			siteId := api.CurrentSiteID()
+			project := server.AddProject(siteId, "Finance")
+			content := []byte("<datasource/>")
+
+			published, err := api.PublishDatasourceFrom(siteId, tableau4go.Datasource{Name: "Sales", Project: &tableau4go.Project{ID: project.ID}},
+				bytes.NewReader(content), test.size(content), "tds", tableau4go.DatasourcePublishOptions{})
+			if err != nil {
+				t.Fatalf("PublishDatasourceFrom: %v", err)
+			}
+			if published.ID == "" || published.Name != "Sales" {
+				t.Fatalf("PublishDatasourceFrom returned %+v", published)
+			}
+			stored, ok := server.DatasourceContent(siteId, published.ID)
+			if !ok || !bytes.Equal(stored, content) {
+				t.Fatalf("server holds %q, want %q", stored, content)
+			}
+			datasources, err := api.QueryDatasources(siteId, "Sales")
+			if err != nil || len(datasources) != 1 || datasources[0].ID != published.ID {
+				t.Fatalf("QueryDatasources returned %+v, %v", datasources, err)
+			}
+		})
+	}
+}
+
+// requests outside the routes the fake serves are answered with a 404, not a dropped connection
+func TestUnknownRouteIsNotFound(t *testing.T) {
+	_, api := signedIn(t)
+	if _, err := api.GetCurrentSession(); !errors.Is(err, tableau4go.ErrNotFound) {
+		t.Fatalf("GetCurrentSession returned %v, want ErrNotFound", err)
+	}
+	if err := api.SwitchSite("other"); !errors.Is(err, tableau4go.ErrNotFound) {
+		t.Fatalf("SwitchSite returned %v, want ErrNotFound", err)
+	}
+}
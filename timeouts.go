@@ -0,0 +1,62 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"time"
+)
+
+// RequestTimeouts bound whole calls, from sending the request until the response is read, including their
+// retries and re-authentication. Publish and Download fall back to Default when zero, a zero Default leaves
+// calls bounded by their context only.
+type RequestTimeouts struct {
+	Default time.Duration
+	// publishing and downloading move whole datasources and workbooks, they usually need far longer than queries
+	Publish  time.Duration
+	Download time.Duration
+}
+
+type operation int
+
+const (
+	operationQuery operation = iota
+	operationPublish
+	operationDownload
+)
+
+type operationKey struct{}
+
+// marks the calls made with ctx as publishing or downloading, for their timeout
+func withOperation(ctx context.Context, op operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+func (timeouts RequestTimeouts) forOperation(op operation) time.Duration {
+	switch {
+	case op == operationPublish && timeouts.Publish > 0:
+		return timeouts.Publish
+	case op == operationDownload && timeouts.Download > 0:
+		return timeouts.Download
+	}
+	return timeouts.Default
+}
+
+// a deadline the caller set on ctx that is earlier still wins
+func (api *API) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	op, _ := ctx.Value(operationKey{}).(operation)
+	timeout := api.Timeouts.forOperation(op)
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,65 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// the server answers -1 instead of a ticket when it won't trust the request
+var ErrTrustedTicketRefused = errors.New("trusted ticket request refused, check the trusted hosts and the user's site membership")
+
+// https://help.tableau.com/current/server/en-us/trusted_auth_webrequ.htm
+// requests a trusted authentication ticket for username. The calling host has to be configured as a trusted
+// host on the server, clientIP is only needed when the server enforces client IP matching.
+func (api *API) GetTrustedTicket(username, siteContentUrl, clientIP string) (string, error) {
+	return api.GetTrustedTicketContext(context.Background(), username, siteContentUrl, clientIP)
+}
+
+func (api *API) GetTrustedTicketContext(ctx context.Context, username, siteContentUrl, clientIP string) (string, error) {
+	requestUrl := fmt.Sprintf("%s/trusted", api.Server)
+	form := url.Values{}
+	form.Set("username", username)
+	if siteName := api.signinSiteName(siteContentUrl); siteName != "" {
+		form.Set("target_site", siteName)
+	}
+	if clientIP != "" {
+		form.Set("client_ip", clientIP)
+	}
+	headers := make(map[string]string)
+	headers[contentTypeHeader] = formContentType
+	body, err := api.makeRequestGetBody(ctx, requestUrl, POST, []byte(form.Encode()), nil, headers)
+	if err != nil {
+		return "", err
+	}
+	ticket := strings.TrimSpace(string(body))
+	if ticket == "" || ticket == "-1" {
+		return "", ErrTrustedTicketRefused
+	}
+	return ticket, nil
+}
+
+// composes the URL that redeems ticket for a view, viewPath is the workbook and sheet part of the view's
+// URL, e.g. "Superstore/Overview"
+func (api *API) TrustedViewURL(ticket, siteContentUrl, viewPath string) string {
+	viewPath = strings.TrimPrefix(viewPath, "/")
+	if siteName := api.signinSiteName(siteContentUrl); siteName != "" {
+		return fmt.Sprintf("%s/trusted/%s/t/%s/views/%s", api.Server, ticket, siteName, viewPath)
+	}
+	return fmt.Sprintf("%s/trusted/%s/views/%s", api.Server, ticket, viewPath)
+}
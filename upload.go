@@ -0,0 +1,130 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// DefaultUploadChunkSize is the size of the chunks an Uploader sends when its ChunkSize is zero, the most
+// Tableau accepts in a single request
+const DefaultUploadChunkSize = 64 << 20
+
+// Uploader sends a file to the server in chunks through a file upload session, for files too large to publish in
+// a single request. Publish the uploaded file with PublishWorkbookUpload or PublishDatasourceUpload.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm
+type Uploader struct {
+	api    *API
+	siteId string
+	// bytes per append request, DefaultUploadChunkSize when zero
+	ChunkSize int64
+	// called after every chunk with the bytes sent so far
+	OnProgress func(sent int64)
+}
+
+func (api *API) NewUploader(siteId string) *Uploader {
+	return &Uploader{api: api, siteId: siteId}
+}
+
+// uploads r and returns the upload session id to publish it with. A negative size reads r to EOF, holding one
+// chunk at a time in memory, otherwise size bytes of r are streamed.
+func (u *Uploader) Upload(r io.Reader, size int64) (string, error) {
+	return u.UploadContext(context.Background(), r, size)
+}
+
+func (u *Uploader) UploadContext(ctx context.Context, r io.Reader, size int64) (string, error) {
+	uploadSessionId, err := u.InitiateContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	chunkSize := u.chunkSize()
+	var sent int64
+	if size >= 0 {
+		for sent < size {
+			chunk := chunkSize
+			if size-sent < chunk {
+				chunk = size - sent
+			}
+			if err := u.AppendContext(ctx, uploadSessionId, r, chunk); err != nil {
+				return "", err
+			}
+			sent += chunk
+			u.progress(sent)
+		}
+		return uploadSessionId, nil
+	}
+	// the buffer only grows as large as the chunks read
+	var buf bytes.Buffer
+	for {
+		buf.Reset()
+		n, err := io.CopyN(&buf, r, chunkSize)
+		if n > 0 {
+			if err := u.AppendContext(ctx, uploadSessionId, bytes.NewReader(buf.Bytes()), n); err != nil {
+				return "", err
+			}
+			sent += n
+			u.progress(sent)
+		}
+		if err == io.EOF {
+			return uploadSessionId, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#initiate_file_upload
+func (u *Uploader) Initiate() (string, error) {
+	return u.InitiateContext(context.Background())
+}
+
+func (u *Uploader) InitiateContext(ctx context.Context) (string, error) {
+	headers := make(map[string]string)
+	headers[contentTypeHeader] = u.api.codec().ContentType()
+	retval := FileUploadResponse{}
+	err := u.api.makeRequest(ctx, u.uploadsUrl(), POST, nil, &retval, headers)
+	return retval.FileUpload.UploadSessionID, err
+}
+
+// sends the next n bytes of r to the upload session, n shouldn't exceed DefaultUploadChunkSize
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#append_to_file_upload
+func (u *Uploader) Append(uploadSessionId string, r io.Reader, n int64) error {
+	return u.AppendContext(context.Background(), uploadSessionId, r, n)
+}
+
+func (u *Uploader) AppendContext(ctx context.Context, uploadSessionId string, r io.Reader, n int64) error {
+	requestUrl := fmt.Sprintf("%s/%s", u.uploadsUrl(), uploadSessionId)
+	prefix, suffix := u.api.multipartParts(nil, "tableau_file", "file")
+	_, err := u.api.sendRequest(withOperation(ctx, operationPublish), requestUrl, PUT, nil, newUploadBody(prefix, r, n, suffix), &FileUploadResponse{}, u.api.multipartHeaders())
+	return err
+}
+
+func (u *Uploader) uploadsUrl() string {
+	return fmt.Sprintf("%s/api/%s/sites/%s/fileUploads", u.api.Server, u.api.Version, u.siteId)
+}
+
+func (u *Uploader) chunkSize() int64 {
+	if u.ChunkSize > 0 {
+		return u.ChunkSize
+	}
+	return DefaultUploadChunkSize
+}
+
+func (u *Uploader) progress(sent int64) {
+	if u.OnProgress != nil {
+		u.OnProgress(sent)
+	}
+}
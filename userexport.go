@@ -0,0 +1,125 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportJSON ExportFormat = "json"
+)
+
+// one row of an access review export
+type UserExportRecord struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	FullName    string   `json:"fullName"`
+	Email       string   `json:"email"`
+	SiteRole    string   `json:"siteRole"`
+	AuthSetting string   `json:"authSetting"`
+	LastLogin   string   `json:"lastLogin"`
+	Groups      []string `json:"groups"`
+}
+
+var userExportCSVHeader = []string{"id", "name", "fullName", "email", "siteRole", "authSetting", "lastLogin", "groups"}
+
+// collects every user of the site along with the names of the groups they belong to
+func (api *API) GetUserExportRecords(siteId string) ([]UserExportRecord, error) {
+	return api.GetUserExportRecordsContext(context.Background(), siteId)
+}
+
+func (api *API) GetUserExportRecordsContext(ctx context.Context, siteId string) ([]UserExportRecord, error) {
+	users, err := api.QueryUsersOnSiteContext(ctx, siteId)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := api.QueryGroupsContext(ctx, siteId)
+	if err != nil {
+		return nil, err
+	}
+	groupNames := map[string][]string{}
+	for _, group := range groups {
+		members, err := api.GetUsersInGroupContext(ctx, siteId, group.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			groupNames[member.ID] = append(groupNames[member.ID], group.Name)
+		}
+	}
+	records := make([]UserExportRecord, 0, len(users))
+	for _, user := range users {
+		memberOf := groupNames[user.ID]
+		if memberOf == nil {
+			memberOf = []string{}
+		}
+		sort.Strings(memberOf)
+		records = append(records, UserExportRecord{
+			ID:          user.ID,
+			Name:        user.Name,
+			FullName:    user.FullName,
+			Email:       user.Email,
+			SiteRole:    string(user.SiteRole),
+			AuthSetting: string(user.AuthSetting),
+			LastLogin:   user.LastLogin,
+			Groups:      memberOf,
+		})
+	}
+	return records, nil
+}
+
+// writes all site users with their roles, authentication, last login and group memberships to w. In CSV the
+// groups of a user are joined with ";".
+func (api *API) ExportUsers(siteId string, w io.Writer, format ExportFormat) error {
+	return api.ExportUsersContext(context.Background(), siteId, w, format)
+}
+
+func (api *API) ExportUsersContext(ctx context.Context, siteId string, w io.Writer, format ExportFormat) error {
+	// collecting the records takes a request per group, don't make them for a format that can't be written
+	if format != ExportJSON && format != ExportCSV {
+		return fmt.Errorf("unsupported export format '%s'", format)
+	}
+	records, err := api.GetUserExportRecordsContext(ctx, siteId)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case ExportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case ExportCSV:
+		writer := csv.NewWriter(w)
+		if err = writer.Write(userExportCSVHeader); err != nil {
+			return err
+		}
+		for _, r := range records {
+			row := []string{r.ID, r.Name, r.FullName, r.Email, r.SiteRole, r.AuthSetting, r.LastLogin, strings.Join(r.Groups, ";")}
+			if err = writer.Write(row); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// returns every user of the site, filters narrow them down on the server,
+// e.g. QueryUsersOnSite(siteId, Filter().Eq("siteRole", "Creator").Gte("lastLogin", since))
+func (api *API) QueryUsersOnSite(siteId string, opts ...QueryOption) ([]User, error) {
+	return api.QueryUsersOnSiteContext(context.Background(), siteId, opts...)
+}
+
+func (api *API) QueryUsersOnSiteContext(ctx context.Context, siteId string, opts ...QueryOption) ([]User, error) {
+	totalAvailable := 1
+	users := []User{}
+	for i := 1; len(users) < totalAvailable; i++ {
+		usersResponse, err := api.QueryUsersOnSiteByPageContext(ctx, siteId, i, opts...)
+		if err != nil {
+			return users, err
+		}
+		users = append(users, usersResponse.Users.Users...)
+		totalAvailable = usersResponse.Pagination.TotalAvailable
+	}
+	return users, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#get_users_on_site
+func (api *API) QueryUsersOnSiteByPage(siteId string, pageNum int, opts ...QueryOption) (QueryUsersResponse, error) {
+	return api.QueryUsersOnSiteByPageContext(context.Background(), siteId, pageNum, opts...)
+}
+
+func (api *API) QueryUsersOnSiteByPageContext(ctx context.Context, siteId string, pageNum int, opts ...QueryOption) (QueryUsersResponse, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/users", api.Server, api.Version, siteId), pageNum, opts)
+	headers := make(map[string]string)
+	response := QueryUsersResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response, err
+}
+
+// looks the user up with a server side filter rather than listing every user, fails with ErrNotFound.
+// Names are matched case insensitively, as the server does when users sign in.
+func (api *API) GetUserByName(siteId, name string) (User, error) {
+	return api.GetUserByNameContext(context.Background(), siteId, name)
+}
+
+func (api *API) GetUserByNameContext(ctx context.Context, siteId, name string) (User, error) {
+	opts := []QueryOption{}
+	// a name holding a comma can't be filtered on, it's matched in the full list below
+	if filterable(name) {
+		opts = append(opts, Filter().Eq("name", name))
+	}
+	users, err := api.QueryUsersOnSiteContext(ctx, siteId, opts...)
+	if err != nil {
+		return User{}, err
+	}
+	for _, user := range users {
+		if strings.EqualFold(user.Name, name) {
+			return user, nil
+		}
+	}
+	return User{}, fmt.Errorf("User Named '%s' %w", name, ErrNotFound)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#add_user_to_site
+// an empty authSetting leaves it to the server's default
+func (api *API) AddUserToSite(siteId, name string, siteRole SiteRole, authSetting AuthSetting) (User, error) {
+	return api.AddUserToSiteContext(context.Background(), siteId, name, siteRole, authSetting)
+}
+
+func (api *API) AddUserToSiteContext(ctx context.Context, siteId, name string, siteRole SiteRole, authSetting AuthSetting) (User, error) {
+	return api.addUserToSite(ctx, siteId, User{Name: name, SiteRole: siteRole, AuthSetting: authSetting})
+}
+
+func (api *API) addUserToSite(ctx context.Context, siteId string, user User) (User, error) {
+	if err := validateRoles(user.SiteRole, user.AuthSetting); err != nil {
+		return User{}, err
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/users", api.Server, api.Version, siteId)
+	payload, headers, err := api.encodeRequest(AddUserRequest{Request: user})
+	if err != nil {
+		return User{}, err
+	}
+	retval := UserResponse{}
+	err = api.makeRequest(ctx, requestUrl, POST, payload, &retval, headers)
+	return retval.User, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#update_user
+// only the attributes set on user are changed
+func (api *API) updateUser(ctx context.Context, siteId, userId string, user User) (User, error) {
+	if err := validateRoles(user.SiteRole, user.AuthSetting); err != nil {
+		return User{}, err
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/users/%s", api.Server, api.Version, siteId, userId)
+	payload, headers, err := api.encodeRequest(AddUserRequest{Request: user})
+	if err != nil {
+		return User{}, err
+	}
+	retval := UserResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.User, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#update_user
+// e.g. UpdateUser(siteId, userId, UserUpdate{SiteRole: SiteRoleViewer, Email: String("jane@example.com")})
+func (api *API) UpdateUser(siteId, userId string, update UserUpdate) (User, error) {
+	return api.UpdateUserContext(context.Background(), siteId, userId, update)
+}
+
+func (api *API) UpdateUserContext(ctx context.Context, siteId, userId string, update UserUpdate) (User, error) {
+	if err := validateRoles(update.SiteRole, update.AuthSetting); err != nil {
+		return User{}, err
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/users/%s", api.Server, api.Version, siteId, userId)
+	payload, headers, err := api.encodeRequest(UpdateUserRequest{Request: update})
+	if err != nil {
+		return User{}, err
+	}
+	retval := UserResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.User, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#remove_user_from_site
+// fails while the user owns content, see RemoveUserFromSiteMappingAssets
+func (api *API) RemoveUserFromSite(siteId, userId string) error {
+	return api.RemoveUserFromSiteContext(context.Background(), siteId, userId)
+}
+
+func (api *API) RemoveUserFromSiteContext(ctx context.Context, siteId, userId string) error {
+	return api.RemoveUserFromSiteMappingAssetsContext(ctx, siteId, userId, "")
+}
+
+// removes the user and hands the content they own to mapAssetsToUserId in the same call
+func (api *API) RemoveUserFromSiteMappingAssets(siteId, userId, mapAssetsToUserId string) error {
+	return api.RemoveUserFromSiteMappingAssetsContext(context.Background(), siteId, userId, mapAssetsToUserId)
+}
+
+func (api *API) RemoveUserFromSiteMappingAssetsContext(ctx context.Context, siteId, userId, mapAssetsToUserId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/users/%s", api.Server, api.Version, siteId, userId)
+	if mapAssetsToUserId != "" {
+		requestUrl += "?mapAssetsTo=" + url.QueryEscape(mapAssetsToUserId)
+	}
+	return api.delete(ctx, requestUrl)
+}
+
+// moves the user to another authentication type, idpConfigurationId picks the identity provider when the site
+// has several SAML or OpenID Connect configurations and may be left empty otherwise
+func (api *API) UpdateUserAuthentication(siteId, userId string, authSetting AuthSetting, idpConfigurationId string) (User, error) {
+	return api.UpdateUserAuthenticationContext(context.Background(), siteId, userId, authSetting, idpConfigurationId)
+}
+
+func (api *API) UpdateUserAuthenticationContext(ctx context.Context, siteId, userId string, authSetting AuthSetting, idpConfigurationId string) (User, error) {
+	return api.updateUser(ctx, siteId, userId, User{AuthSetting: authSetting, IdpConfigurationID: idpConfigurationId})
+}
@@ -0,0 +1,43 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetUserByName(t *testing.T) {
+	api := listingServer(t, "users", map[string]string{
+		"jsmith":        `<user id="1" name="jsmith" siteRole="Viewer"/>`,
+		"Smith, Jane":   `<user id="2" name="Smith, Jane" siteRole="Creator"/>`,
+		"Doe, John Jr.": `<user id="3" name="Doe, John Jr." siteRole="Explorer"/>`,
+	})
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"jsmith", "1"},
+		{"Smith, Jane", "2"},
+		{"smith, jane", "2"},
+		{"Doe, John Jr.", "3"},
+	}
+	for _, test := range tests {
+		user, err := api.GetUserByName("site", test.name)
+		if err != nil || user.ID != test.want {
+			t.Errorf("GetUserByName(%q) = %+v, %v, want id %s", test.name, user, err, test.want)
+		}
+	}
+	if _, err := api.GetUserByName("site", "Nobody, Else"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetUserByName of a missing user returned %v, want ErrNotFound", err)
+	}
+}
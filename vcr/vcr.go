@@ -0,0 +1,253 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vcr records the REST interactions of a tableau4go client with a real server to sanitized fixture
+// files and replays them, so applications built on tableau4go can be tested without a Tableau Server.
+//
+//	recorder, err := vcr.New("testdata/publish.json", vcr.Replay)
+//	api.Transport = recorder
+//	// in Record mode, call recorder.Save() once done
+package vcr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode selects whether a Recorder talks to the server or plays a fixture back
+type Mode int
+
+const (
+	// Replay answers requests from the fixture and fails those it has no recording for
+	Replay Mode = iota
+	// Record sends requests to the server and keeps the sanitized interactions until Save
+	Record
+)
+
+// Body holds text as is and anything else, like a .tdsx, base64 encoded
+type Body struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+func newBody(data []byte) Body {
+	if utf8.Valid(data) {
+		return Body{Text: string(data)}
+	}
+	return Body{Base64: base64.StdEncoding.EncodeToString(data)}
+}
+
+func (b Body) bytes() ([]byte, error) {
+	if b.Base64 != "" {
+		return base64.StdEncoding.DecodeString(b.Base64)
+	}
+	return []byte(b.Text), nil
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body"`
+}
+
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the content of a fixture file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// headers that carry credentials, dropped from recordings
+var secretHeaders = []string{"X-Tableau-Auth", "Authorization", "Cookie", "Set-Cookie"}
+
+// password="..." and token="..." attributes in xml and "password":"..." in json, which also covers
+// personalAccessTokenSecret and jwt
+var secretValues = regexp.MustCompile(`(?i)((?:password|token|secret|jwt)"?\s*[=:]\s*")[^"]*`)
+
+// Sanitize drops the credential headers of an interaction and masks the passwords, tokens and secrets in
+// its bodies. It is what a Recorder applies when its Sanitize field is nil.
+func Sanitize(interaction *Interaction) {
+	for _, header := range secretHeaders {
+		interaction.Request.Header.Del(header)
+		interaction.Response.Header.Del(header)
+	}
+	for _, body := range []*Body{&interaction.Request.Body, &interaction.Response.Body} {
+		body.Text = secretValues.ReplaceAllString(body.Text, "${1}REDACTED")
+	}
+}
+
+// Recorder is an http.RoundTripper, set it as the Transport of a tableau4go API
+type Recorder struct {
+	// the transport recordings go through, http.DefaultTransport when nil
+	Transport http.RoundTripper
+	// applied to every interaction before it's kept, Sanitize when nil. Add to it, e.g. to mask server
+	// names, rather than replace it.
+	Sanitize func(interaction *Interaction)
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	played   []bool
+}
+
+// New creates a Recorder for the fixture at path, which has to exist in Replay mode
+func New(path string, mode Mode) (*Recorder, error) {
+	recorder := &Recorder{mode: mode, path: path}
+	if mode == Record {
+		return recorder, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &recorder.cassette); err != nil {
+		return nil, fmt.Errorf("vcr: reading %s: %w", path, err)
+	}
+	recorder.played = make([]bool, len(recorder.cassette.Interactions))
+	return recorder, nil
+}
+
+// requests match on method, path and query, the server's address may differ between recording and replay
+func matchKey(method string, requestUrl string) string {
+	if i := strings.Index(requestUrl, "://"); i >= 0 {
+		requestUrl = requestUrl[i+3:]
+		if j := strings.Index(requestUrl, "/"); j >= 0 {
+			requestUrl = requestUrl[j:]
+		}
+	}
+	return method + " " + requestUrl
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		if requestBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	if r.mode == Replay {
+		return r.replay(req)
+	}
+	return r.record(req, requestBody)
+}
+
+// interactions are played back in the order they were recorded, so repeated requests get the answers the
+// server gave at each point
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := matchKey(req.Method, req.URL.String())
+	for i, interaction := range r.cassette.Interactions {
+		if r.played[i] || matchKey(interaction.Request.Method, interaction.Request.URL) != key {
+			continue
+		}
+		r.played[i] = true
+		body, err := interaction.Response.Body.bytes()
+		if err != nil {
+			return nil, err
+		}
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("vcr: no recorded interaction left for %s in %s", key, r.path)
+}
+
+func (r *Recorder) record(req *http.Request, requestBody []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	sent := req.Clone(req.Context())
+	sent.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	// recordings are kept uncompressed so they can be sanitized and read
+	sent.Header.Del("Accept-Encoding")
+	resp, err := transport.RoundTrip(sent)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(bytes.NewReader(responseBody))
+		if err != nil {
+			return nil, err
+		}
+		if responseBody, err = ioutil.ReadAll(gz); err != nil {
+			return nil, err
+		}
+		resp.Header.Del("Content-Encoding")
+	}
+	interaction := Interaction{
+		Request:  Request{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: newBody(requestBody)},
+		Response: Response{StatusCode: resp.StatusCode, Header: resp.Header.Clone(), Body: newBody(responseBody)},
+	}
+	sanitize := r.Sanitize
+	if sanitize == nil {
+		sanitize = Sanitize
+	}
+	sanitize(&interaction)
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+	resp.ContentLength = int64(len(responseBody))
+	return resp, nil
+}
+
+// Save writes the interactions recorded so far to the fixture file
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, data, 0o600)
+}
@@ -0,0 +1,99 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vcr
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtScaleInc/tableau4go"
+)
+
+const token = "s3cr3t-session-token"
+const password = "hunter2"
+
+func tableauServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/auth/signin"):
+			w.Write([]byte(`<tsResponse><credentials token="` + token + `"><site id="site" contentUrl=""/><user id="admin"/></credentials></tsResponse>`))
+		case r.Header.Get("X-Tableau-Auth") != token:
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasSuffix(r.URL.Path, "/projects"):
+			w.Write([]byte(`<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="1"/><projects><project id="p1" name="Finance"/></projects></tsResponse>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func queryProjects(t *testing.T, api tableau4go.API) {
+	t.Helper()
+	if err := api.Signin("admin", password, "", ""); err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	projects, err := api.QueryProjects(api.CurrentSiteID())
+	if err != nil || len(projects) != 1 || projects[0].Name != "Finance" {
+		t.Fatalf("QueryProjects returned %+v, %v", projects, err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.json")
+	server := tableauServer(t)
+
+	recorder, err := New(path, Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := tableau4go.NewAPI(server.URL, "3.4", tableau4go.BoundaryString, "", true, time.Second, time.Second)
+	api.Transport = recorder
+	queryProjects(t, api)
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	fixture, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{token, password, "X-Tableau-Auth"} {
+		if strings.Contains(string(fixture), secret) {
+			t.Errorf("the fixture holds %q:\n%s", secret, fixture)
+		}
+	}
+	if !strings.Contains(string(fixture), `token=\"REDACTED\"`) {
+		t.Errorf("the fixture doesn't hold the redacted token:\n%s", fixture)
+	}
+
+	// replayed from the fixture, the server is gone and the address doesn't matter
+	server.Close()
+	replayer, err := New(path, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api = tableau4go.NewAPI("http://tableau.invalid", "3.4", tableau4go.BoundaryString, "", true, time.Second, time.Second)
+	api.Transport = replayer
+	queryProjects(t, api)
+	if _, err = api.QueryProjects("site"); err == nil {
+		t.Fatal("a request beyond the recording was answered")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrEndpointUnavailable is matched by errors.Is when an endpoint doesn't exist on the server, not even at the
+// REST API version it advertises
+var ErrEndpointUnavailable = errors.New("endpoint unavailable on this server")
+
+type EndpointUnavailableError struct {
+	URL string
+	// the version the request was made with and the one the server advertises
+	Version       string
+	ServerVersion string
+	Err           error
+}
+
+func (e *EndpointUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s requested with REST API %s, server supports %s: %v", ErrEndpointUnavailable, e.URL, e.Version, e.ServerVersion, e.Err)
+}
+
+func (e *EndpointUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+func (e *EndpointUnavailableError) Is(target error) bool {
+	return target == ErrEndpointUnavailable
+}
+
+var apiVersionPath = regexp.MustCompile(`/api/(\d+(?:\.\d+)*)/`)
+
+func isEndpointMissing(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && (statusErr.Code == http.StatusNotFound || statusErr.Code == http.StatusMethodNotAllowed)
+}
+
+func (api *API) retryWithServerVersion(ctx context.Context, requestUrl string, method string, payload []byte, upload *uploadBody, result interface{}, headers map[string]string, err error) ([]byte, error) {
+	match := apiVersionPath.FindStringSubmatch(requestUrl)
+	// serverinfo is how the version is found, falling back on it would never end
+	if match == nil || strings.HasSuffix(requestUrl, "/serverinfo") {
+		return nil, err
+	}
+	serverVersion, infoErr := api.serverRestVersion(ctx)
+	if infoErr != nil || serverVersion == "" {
+		return nil, err
+	}
+	// at or below the server's version the endpoint exists, so this is a resource that isn't there
+	if compareVersions(match[1], serverVersion) <= 0 {
+		return nil, err
+	}
+	fallbackUrl := strings.Replace(requestUrl, match[0], "/api/"+serverVersion+"/", 1)
+	api.loggerFor(ctx).Infof("t4g retrying with REST API %s:%v", serverVersion, fallbackUrl)
+	body, fallbackErr := api.doRequestWithRetry(ctx, fallbackUrl, method, payload, upload, result, headers)
+	if isEndpointMissing(fallbackErr) {
+		return body, &EndpointUnavailableError{URL: requestUrl, Version: match[1], ServerVersion: serverVersion, Err: fallbackErr}
+	}
+	return body, fallbackErr
+}
+
+// the REST API version the server advertises, kept by the APIs created with NewAPI so a fallback doesn't
+// cost a serverinfo request every time
+type serverVersionCache struct {
+	mu      sync.Mutex
+	version string
+}
+
+func (api *API) serverRestVersion(ctx context.Context) (string, error) {
+	if cache := api.serverVersion; cache != nil {
+		cache.mu.Lock()
+		version := cache.version
+		cache.mu.Unlock()
+		if version != "" {
+			return version, nil
+		}
+	}
+	serverInfo, err := api.ServerInfoContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	if cache := api.serverVersion; cache != nil {
+		cache.mu.Lock()
+		cache.version = serverInfo.RestApiVersion
+		cache.mu.Unlock()
+	}
+	return serverInfo.RestApiVersion, nil
+}
+
+// compares dotted versions numerically so that 3.10 is newer than 3.9, returns -1, 0 or 1
+func compareVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// ErrServerVersionTooOld is matched by errors.Is when Negotiate finds a server older than API.MinVersion
+var ErrServerVersionTooOld = errors.New("server REST API version older than required")
+
+// Negotiate sets api.Version to the REST API version the server advertises, capped at api.MaxVersion when set.
+// It fails when the server is older than api.MinVersion, leaving api.Version as it was.
+func (api *API) Negotiate() (string, error) {
+	return api.NegotiateContext(context.Background())
+}
+
+func (api *API) NegotiateContext(ctx context.Context) (string, error) {
+	serverInfo, err := api.ServerInfoContext(ctx)
+	if err != nil {
+		return api.Version, err
+	}
+	version := serverInfo.RestApiVersion
+	if version == "" {
+		return api.Version, fmt.Errorf("server %s didn't advertise its REST API version", api.Server)
+	}
+	if api.MinVersion != "" && compareVersions(version, api.MinVersion) < 0 {
+		return api.Version, fmt.Errorf("%w: server supports %s, at least %s required", ErrServerVersionTooOld, version, api.MinVersion)
+	}
+	if api.MaxVersion != "" && compareVersions(version, api.MaxVersion) > 0 {
+		version = api.MaxVersion
+	}
+	api.loggerFor(ctx).Debugf("t4g negotiated REST API version %s, server supports %s", version, serverInfo.RestApiVersion)
+	api.Version = version
+	return version, nil
+}
+
+// ErrUnsupportedVersion is matched by errors.Is when a call needs a newer REST API version than api.Version
+var ErrUnsupportedVersion = errors.New("endpoint unsupported at this REST API version")
+
+type UnsupportedVersionError struct {
+	Endpoint   string
+	Version    string
+	MinVersion string
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("%s: %s needs REST API %s, the client uses %s", ErrUnsupportedVersion, e.Endpoint, e.MinVersion, e.Version)
+}
+
+func (e *UnsupportedVersionError) Is(target error) bool {
+	return target == ErrUnsupportedVersion
+}
+
+// the REST API version each endpoint was introduced with, paths are matched by prefix and * matches a segment
+var endpointVersions = []struct {
+	path    string
+	version string
+}{
+	{"serverinfo", "2.4"},
+	{"auth/switchSite", "2.6"},
+	{"sites/*/datasources/*/revisions", "2.3"},
+	{"sites/*/workbooks/*/revisions", "2.3"},
+	{"sites/*/datasources/*/refresh", "2.8"},
+	{"sites/*/views/*/data", "2.8"},
+	{"sites/*/encrypt-extracts", "3.5"},
+	{"sites/*/decrypt-extracts", "3.5"},
+	{"sites/*/reencrypt-extracts", "3.5"},
+	{"sites/*/projects/*/default-permissions/flows", "3.3"},
+	{"sites/*/flows", "3.3"},
+	{"sites/*/webhooks", "3.6"},
+	{"sites/*/users/*/groups", "3.7"},
+	{"sites/*/virtualConnections", "3.18"},
+	{"sites/*/site-auth-configurations", "3.24"},
+}
+
+// the version the endpoint of requestUrl needs, empty when it isn't known to need one
+func minimumVersion(requestUrl string) string {
+	parsed, err := url.Parse(requestUrl)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(apiPathPrefix.ReplaceAllString(parsed.Path, ""), "/"), "/")
+	for _, endpoint := range endpointVersions {
+		pattern := strings.Split(endpoint.path, "/")
+		if len(pattern) > len(segments) {
+			continue
+		}
+		matched := true
+		for i, part := range pattern {
+			if part != "*" && part != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return endpoint.version
+		}
+	}
+	return ""
+}
+
+// fails calls the server can't answer at the version of requestUrl before they are sent, instead of with a 404
+func checkVersion(requestUrl string) error {
+	match := apiVersionPath.FindStringSubmatch(requestUrl)
+	if match == nil {
+		return nil
+	}
+	if minVersion := minimumVersion(requestUrl); minVersion != "" && compareVersions(match[1], minVersion) < 0 {
+		return &UnsupportedVersionError{Endpoint: endpointLabel(requestUrl), Version: match[1], MinVersion: minVersion}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"3.4", "3.4", 0},
+		{"3.10", "3.9", 1},
+		{"3.9", "3.10", -1},
+		{"2.8", "3.0", -1},
+		{"3", "3.0", 0},
+		{"3.0.1", "3.0", 1},
+		{"3.4", "3.4.0", 0},
+		{"", "2.3", -1},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestMinimumVersion(t *testing.T) {
+	const server = "https://tableau.example.com"
+	tests := []struct {
+		requestUrl string
+		want       string
+	}{
+		{server + "/api/3.4/serverinfo", "2.4"},
+		{server + "/api/3.4/auth/switchSite", "2.6"},
+		{server + "/api/3.4/auth/signin", ""},
+		{server + "/api/3.4/sites/abc/workbooks/def/revisions?pageSize=100&pageNumber=1", "2.3"},
+		{server + "/api/3.4/sites/abc/workbooks/def", ""},
+		{server + "/api/3.4/sites/abc/views/def/data", "2.8"},
+		{server + "/api/3.4/sites/abc/users/def/groups", "3.7"},
+		{server + "/api/3.4/sites/abc/users/def", ""},
+		{server + "/api/3.4/sites/abc/projects/def/default-permissions/flows", "3.3"},
+		{server + "/api/3.4/sites/abc/projects/def/default-permissions/workbooks", ""},
+		{server + "/api/3.4/sites/abc/flows/def", "3.3"},
+		{server + "/api/3.4/sites/abc/virtualConnections", "3.18"},
+		{server + "/api/3.4/sites", ""},
+		{"::not a url", ""},
+	}
+	for _, test := range tests {
+		if got := minimumVersion(test.requestUrl); got != test.want {
+			t.Errorf("minimumVersion(%q) = %q, want %q", test.requestUrl, got, test.want)
+		}
+	}
+}
+
+// a server at REST API 3.4 serving projects, the serverinfo requests it answered are counted
+func fallbackServer(t *testing.T, serverInfoRequests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		switch r.URL.Path {
+		case "/api/2.4/serverinfo":
+			*serverInfoRequests++
+			fmt.Fprint(w, `<tsResponse><serverInfo><productVersion>2023.1</productVersion><restApiVersion>3.4</restApiVersion></serverInfo></tsResponse>`)
+		case "/api/3.4/sites/site/projects":
+			fmt.Fprint(w, `<tsResponse><pagination pageNumber="1" pageSize="100" totalAvailable="1"/><projects><project id="1" name="Default"/></projects></tsResponse>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<tsResponse><error code="404000"><summary>Resource Not Found</summary><detail>missing</detail></error></tsResponse>`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVersionFallback(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         string
+		call            func(api *API) error
+		wantUnavailable bool
+	}{
+		{
+			name:    "falls back to the server's version",
+			version: "3.20",
+			call: func(api *API) error {
+				projects, err := api.QueryProjects("site")
+				if err == nil && len(projects) != 1 {
+					return fmt.Errorf("got %d projects", len(projects))
+				}
+				return err
+			},
+		},
+		{
+			name:            "missing at the server's version too",
+			version:         "3.20",
+			call:            func(api *API) error { _, err := api.QueryUserOnSite("site", "user"); return err },
+			wantUnavailable: true,
+		},
+		{
+			name:    "a missing resource at a supported version",
+			version: "3.4",
+			call:    func(api *API) error { _, err := api.QueryUserOnSite("site", "user"); return err },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serverInfoRequests := 0
+			server := fallbackServer(t, &serverInfoRequests)
+			api := NewAPI(server.URL, test.version, BoundaryString, "", true, time.Second, time.Second)
+			api.VersionFallback = true
+			for i := 0; i < 2; i++ {
+				err := test.call(&api)
+				if unavailable := errors.Is(err, ErrEndpointUnavailable); unavailable != test.wantUnavailable {
+					t.Fatalf("errors.Is(%v, ErrEndpointUnavailable) = %v, want %v", err, unavailable, test.wantUnavailable)
+				}
+				if test.wantUnavailable || test.version == "3.4" {
+					if !errors.Is(err, ErrNotFound) {
+						t.Fatalf("errors.Is(%v, ErrNotFound) = false", err)
+					}
+				} else if err != nil {
+					t.Fatalf("call failed: %v", err)
+				}
+			}
+			if serverInfoRequests != 1 {
+				t.Fatalf("the server version was requested %d times, want once", serverInfoRequests)
+			}
+		})
+	}
+}
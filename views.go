@@ -0,0 +1,119 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+func (api *API) QueryViewsForSite(siteId string, includeUsage bool, opts ...QueryOption) ([]View, error) {
+	return api.QueryViewsForSiteContext(context.Background(), siteId, includeUsage, opts...)
+}
+
+func (api *API) QueryViewsForSiteContext(ctx context.Context, siteId string, includeUsage bool, opts ...QueryOption) ([]View, error) {
+	totalAvailable := 1
+	views := []View{}
+	for i := 1; len(views) < totalAvailable; i++ {
+		viewsResponse, err := api.QueryViewsForSiteByPageContext(ctx, siteId, includeUsage, i, opts...)
+		if err != nil {
+			return views, err
+		}
+		// an empty page means views were deleted while paging
+		if len(viewsResponse.Views.Views) == 0 {
+			break
+		}
+		views = append(views, viewsResponse.Views.Views...)
+		totalAvailable = viewsResponse.Pagination.TotalAvailable
+	}
+	return views, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_views_for_site
+// the usage holds the total view count of each view
+func (api *API) QueryViewsForSiteByPage(siteId string, includeUsage bool, pageNum int, opts ...QueryOption) (QueryViewsResponse, error) {
+	return api.QueryViewsForSiteByPageContext(context.Background(), siteId, includeUsage, pageNum, opts...)
+}
+
+func (api *API) QueryViewsForSiteByPageContext(ctx context.Context, siteId string, includeUsage bool, pageNum int, opts ...QueryOption) (QueryViewsResponse, error) {
+	opts = append([]QueryOption{queryOptionFunc(func(values url.Values) {
+		values.Set("includeUsageStatistics", strconv.FormatBool(includeUsage))
+	})}, opts...)
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/views", api.Server, api.Version, siteId), pageNum, opts)
+	headers := make(map[string]string)
+	response := QueryViewsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_views_for_workbook
+// the sheets, dashboards and stories of the workbook, the server returns them all at once
+func (api *API) QueryViewsForWorkbook(siteId, workbookId string, includeUsage bool) ([]View, error) {
+	return api.QueryViewsForWorkbookContext(context.Background(), siteId, workbookId, includeUsage)
+}
+
+func (api *API) QueryViewsForWorkbookContext(ctx context.Context, siteId, workbookId string, includeUsage bool) ([]View, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s/views?includeUsageStatistics=%v", api.Server, api.Version, siteId, workbookId, includeUsage)
+	headers := make(map[string]string)
+	response := QueryViewsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response.Views.Views, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#get_view
+func (api *API) GetView(siteId, viewId string) (View, error) {
+	return api.GetViewContext(context.Background(), siteId, viewId)
+}
+
+func (api *API) GetViewContext(ctx context.Context, siteId, viewId string) (View, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/views/%s", api.Server, api.Version, siteId, viewId)
+	headers := make(map[string]string)
+	retval := ViewResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.View, err
+}
+
+// looks the view up by the name of its workbook and its own name or url name, as in the view's content url
+// Sales/sheets/Overview. Workbooks in different projects may share a name, the first match is returned.
+// Fails with ErrNotFound.
+func (api *API) GetViewByPath(siteId, workbookName, viewName string) (View, error) {
+	return api.GetViewByPathContext(context.Background(), siteId, workbookName, viewName)
+}
+
+func (api *API) GetViewByPathContext(ctx context.Context, siteId, workbookName, viewName string) (View, error) {
+	views, err := api.QueryViewsForSiteContext(ctx, siteId, false, Filter().Eq("workbookName", workbookName))
+	if err != nil {
+		return View{}, err
+	}
+	for _, view := range views {
+		if view.Name == viewName || view.ViewUrlName == viewName {
+			return view, nil
+		}
+	}
+	return View{}, fmt.Errorf("View Named '%s/%s' %w", workbookName, viewName, ErrNotFound)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_content_exploration.htm#get_recently_viewed_for_site
+// the workbooks and views the signed in user viewed lately on the site, most recent first
+func (api *API) GetRecentlyViewed(siteId string) ([]Recent, error) {
+	return api.GetRecentlyViewedContext(context.Background(), siteId)
+}
+
+func (api *API) GetRecentlyViewedContext(ctx context.Context, siteId string) ([]Recent, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/content/recent", api.Server, api.Version, siteId)
+	headers := make(map[string]string)
+	retval := RecentlyViewedResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.Recents.Recents, err
+}
@@ -0,0 +1,76 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+)
+
+func (api *API) QueryVirtualConnections(siteId string) ([]VirtualConnection, error) {
+	return api.QueryVirtualConnectionsContext(context.Background(), siteId)
+}
+
+func (api *API) QueryVirtualConnectionsContext(ctx context.Context, siteId string) ([]VirtualConnection, error) {
+	totalAvailable := 1
+	virtualConnections := []VirtualConnection{}
+	for i := 1; len(virtualConnections) < totalAvailable; i++ {
+		requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/virtualConnections?pageSize=%v&pageNumber=%v", api.Server, api.Version, siteId, PAGESIZE, i)
+		headers := make(map[string]string)
+		response := QueryVirtualConnectionsResponse{}
+		if err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers); err != nil {
+			return virtualConnections, err
+		}
+		virtualConnections = append(virtualConnections, response.VirtualConnections.VirtualConnections...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return virtualConnections, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_virtual_connections.htm#ListVirtualConnectionDatabaseConnections
+func (api *API) QueryVirtualConnectionConnections(siteId, virtualConnectionId string) ([]Connection, error) {
+	return api.QueryVirtualConnectionConnectionsContext(context.Background(), siteId, virtualConnectionId)
+}
+
+func (api *API) QueryVirtualConnectionConnectionsContext(ctx context.Context, siteId, virtualConnectionId string) ([]Connection, error) {
+	totalAvailable := 1
+	connections := []Connection{}
+	for i := 1; len(connections) < totalAvailable; i++ {
+		requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/virtualConnections/%s/connections?pageSize=%v&pageNumber=%v",
+			api.Server, api.Version, siteId, virtualConnectionId, PAGESIZE, i)
+		headers := make(map[string]string)
+		response := QueryConnectionsResponse{}
+		if err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers); err != nil {
+			return connections, err
+		}
+		connections = append(connections, response.Connections.Connections...)
+		totalAvailable = response.Pagination.TotalAvailable
+	}
+	return connections, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_virtual_connections.htm#UpdateVirtualConnectionDBConnections
+// use this to rotate the credentials or move the server of a governed connection
+func (api *API) UpdateVirtualConnectionConnection(siteId, virtualConnectionId, connectionId string, update ConnectionUpdate) (Connection, error) {
+	return api.UpdateVirtualConnectionConnectionContext(context.Background(), siteId, virtualConnectionId, connectionId, update)
+}
+
+func (api *API) UpdateVirtualConnectionConnectionContext(ctx context.Context, siteId, virtualConnectionId, connectionId string, update ConnectionUpdate) (Connection, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/virtualConnections/%s/connections/%s/modify", api.Server, api.Version, siteId, virtualConnectionId, connectionId)
+	payload, headers, err := api.encodeRequest(UpdateConnectionRequest{Request: update})
+	if err != nil {
+		return Connection{}, err
+	}
+	retval := UpdateConnectionResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.Connection, err
+}
@@ -0,0 +1,172 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+)
+
+func (api *API) QueryWorkbooks(siteId string, opts ...QueryOption) ([]Workbook, error) {
+	return api.QueryWorkbooksContext(context.Background(), siteId, opts...)
+}
+
+func (api *API) QueryWorkbooksContext(ctx context.Context, siteId string, opts ...QueryOption) ([]Workbook, error) {
+	totalAvailable := 1
+	workbooks := []Workbook{}
+	for i := 1; len(workbooks) < totalAvailable; i++ {
+		workbooksResponse, err := api.QueryWorkbooksByPageContext(ctx, siteId, i, opts...)
+		if err != nil {
+			return workbooks, err
+		}
+		workbooks = append(workbooks, workbooksResponse.Workbooks.Workbooks...)
+		totalAvailable = workbooksResponse.Pagination.TotalAvailable
+	}
+	return workbooks, nil
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_workbooks_for_site
+func (api *API) QueryWorkbooksByPage(siteId string, pageNum int, opts ...QueryOption) (QueryWorkbooksResponse, error) {
+	return api.QueryWorkbooksByPageContext(context.Background(), siteId, pageNum, opts...)
+}
+
+func (api *API) QueryWorkbooksByPageContext(ctx context.Context, siteId string, pageNum int, opts ...QueryOption) (QueryWorkbooksResponse, error) {
+	requestUrl := pagedUrl(fmt.Sprintf("%s/api/%s/sites/%s/workbooks", api.Server, api.Version, siteId), pageNum, opts)
+	headers := make(map[string]string)
+	response := QueryWorkbooksResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &response, headers)
+	return response, err
+}
+
+// returns the workbook with its project, owner, tags and views
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_workbook
+func (api *API) GetWorkbook(siteId, workbookId string) (Workbook, error) {
+	return api.GetWorkbookContext(context.Background(), siteId, workbookId)
+}
+
+func (api *API) GetWorkbookContext(ctx context.Context, siteId, workbookId string) (Workbook, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s", api.Server, api.Version, siteId, workbookId)
+	headers := make(map[string]string)
+	retval := WorkbookResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.Workbook, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#update_workbook
+// e.g. UpdateWorkbook(siteId, workbookId, WorkbookUpdate{Project: &Project{ID: projectId}, Owner: &User{ID: userId}})
+func (api *API) UpdateWorkbook(siteId, workbookId string, update WorkbookUpdate) (Workbook, error) {
+	return api.UpdateWorkbookContext(context.Background(), siteId, workbookId, update)
+}
+
+func (api *API) UpdateWorkbookContext(ctx context.Context, siteId, workbookId string, update WorkbookUpdate) (Workbook, error) {
+	// only the ids identify the new project and owner
+	if update.Project != nil {
+		update.Project = &Project{ID: update.Project.ID}
+	}
+	if update.Owner != nil {
+		update.Owner = &User{ID: update.Owner.ID}
+	}
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s", api.Server, api.Version, siteId, workbookId)
+	payload, headers, err := api.encodeRequest(UpdateWorkbookRequest{Request: update})
+	if err != nil {
+		return Workbook{}, err
+	}
+	retval := WorkbookResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.Workbook, err
+}
+
+// looks the workbook up by name in the project, an empty projectId matches the first workbook with the name
+// in any project. Fails with ErrNotFound.
+func (api *API) GetWorkbookByName(siteId, projectId, name string) (Workbook, error) {
+	return api.GetWorkbookByNameContext(context.Background(), siteId, projectId, name)
+}
+
+func (api *API) GetWorkbookByNameContext(ctx context.Context, siteId, projectId, name string) (Workbook, error) {
+	opts := []QueryOption{}
+	// a name holding a comma can't be filtered on, it's matched in the full list below
+	if filterable(name) {
+		opts = append(opts, Filter().Eq("name", name))
+	}
+	workbooks, err := api.QueryWorkbooksContext(ctx, siteId, opts...)
+	if err != nil {
+		return Workbook{}, err
+	}
+	for _, workbook := range workbooks {
+		if workbook.Name != name {
+			continue
+		}
+		if projectId == "" || (workbook.Project != nil && workbook.Project.ID == projectId) {
+			return workbook, nil
+		}
+	}
+	return Workbook{}, fmt.Errorf("Workbook Named '%s' %w", name, ErrNotFound)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#delete_workbook
+func (api *API) DeleteWorkbook(siteId, workbookId string) error {
+	return api.DeleteWorkbookContext(context.Background(), siteId, workbookId)
+}
+
+func (api *API) DeleteWorkbookContext(ctx context.Context, siteId, workbookId string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s", api.Server, api.Version, siteId, workbookId)
+	return api.delete(ctx, requestUrl)
+}
+
+// deletes the workbook named workbookName in the project at projectPath, e.g. "Finance/EMEA"
+func (api *API) DeleteWorkbookByName(siteId, projectPath, workbookName string) error {
+	return api.DeleteWorkbookByNameContext(context.Background(), siteId, projectPath, workbookName)
+}
+
+func (api *API) DeleteWorkbookByNameContext(ctx context.Context, siteId, projectPath, workbookName string) error {
+	project, err := api.ResolveProjectPathContext(ctx, siteId, projectPath, false)
+	if err != nil {
+		return err
+	}
+	workbook, err := api.GetWorkbookByNameContext(ctx, siteId, project.ID, workbookName)
+	if err != nil {
+		return err
+	}
+	return api.DeleteWorkbookContext(ctx, siteId, workbook.ID)
+}
+
+// the database connections of the workbook's datasources, with the datasource each belongs to
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_workbook_connections
+func (api *API) QueryWorkbookConnections(siteId, workbookId string) ([]Connection, error) {
+	return api.QueryWorkbookConnectionsContext(context.Background(), siteId, workbookId)
+}
+
+func (api *API) QueryWorkbookConnectionsContext(ctx context.Context, siteId, workbookId string) ([]Connection, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s/connections", api.Server, api.Version, siteId, workbookId)
+	headers := make(map[string]string)
+	retval := QueryConnectionsResponse{}
+	err := api.makeRequest(ctx, requestUrl, GET, nil, &retval, headers)
+	return retval.Connections.Connections, err
+}
+
+// repoints a connection of the workbook, e.g. to promote it to another environment without republishing:
+// UpdateWorkbookConnection(siteId, workbookId, connectionId, ConnectionUpdate{ServerAddress: String("db.prod"), Password: String(pw), EmbedPassword: Bool(true)})
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#update_workbook_connection
+func (api *API) UpdateWorkbookConnection(siteId, workbookId, connectionId string, update ConnectionUpdate) (Connection, error) {
+	return api.UpdateWorkbookConnectionContext(context.Background(), siteId, workbookId, connectionId, update)
+}
+
+func (api *API) UpdateWorkbookConnectionContext(ctx context.Context, siteId, workbookId, connectionId string, update ConnectionUpdate) (Connection, error) {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s/connections/%s", api.Server, api.Version, siteId, workbookId, connectionId)
+	payload, headers, err := api.encodeRequest(UpdateConnectionRequest{Request: update})
+	if err != nil {
+		return Connection{}, err
+	}
+	retval := UpdateConnectionResponse{}
+	err = api.makeRequest(ctx, requestUrl, PUT, payload, &retval, headers)
+	return retval.Connection, err
+}
@@ -0,0 +1,43 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetWorkbookByName(t *testing.T) {
+	api := listingServer(t, "workbooks", map[string]string{
+		"Sales":       `<workbook id="1" name="Sales"><project id="p1" name="Default"/></workbook>`,
+		"Sales, EMEA": `<workbook id="2" name="Sales, EMEA"><project id="p2" name="Finance"/></workbook>`,
+	})
+	tests := []struct {
+		name      string
+		projectId string
+		want      string
+	}{
+		{"Sales", "", "1"},
+		{"Sales", "p1", "1"},
+		{"Sales, EMEA", "", "2"},
+		{"Sales, EMEA", "p2", "2"},
+	}
+	for _, test := range tests {
+		workbook, err := api.GetWorkbookByName("site", test.projectId, test.name)
+		if err != nil || workbook.ID != test.want {
+			t.Errorf("GetWorkbookByName(%q, %q) = %+v, %v, want id %s", test.projectId, test.name, workbook, err, test.want)
+		}
+	}
+	if _, err := api.GetWorkbookByName("site", "p1", "Sales, EMEA"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetWorkbookByName in another project returned %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// a .tds of a few hundred MB is already unusually large
+const DefaultMaxDecompressedSize = 512 * 1024 * 1024
+const DefaultMaxZipEntries = 1000
+
+var ErrZipLimitExceeded = errors.New("zip archive exceeds the configured limits")
+var ErrZipUnsafePath = errors.New("zip archive contains an unsafe path")
+
+// ZipLimits guards against zip bombs in archives downloaded from the server
+type ZipLimits struct {
+	// total decompressed bytes allowed across all entries
+	MaxDecompressedSize int64
+	MaxEntries          int
+}
+
+func DefaultZipLimits() ZipLimits {
+	return ZipLimits{MaxDecompressedSize: DefaultMaxDecompressedSize, MaxEntries: DefaultMaxZipEntries}
+}
+
+func (api *API) zipLimits() ZipLimits {
+	limits := api.ZipLimits
+	defaults := DefaultZipLimits()
+	if limits.MaxDecompressedSize <= 0 {
+		limits.MaxDecompressedSize = defaults.MaxDecompressedSize
+	}
+	if limits.MaxEntries <= 0 {
+		limits.MaxEntries = defaults.MaxEntries
+	}
+	return limits
+}
+
+// check rejects archives with too many entries, entries that declare too much data or entries whose names
+// would escape the directory they are extracted to
+func (limits ZipLimits) check(r *zip.Reader) error {
+	if len(r.File) > limits.MaxEntries {
+		return fmt.Errorf("%w: %d entries, at most %d allowed", ErrZipLimitExceeded, len(r.File), limits.MaxEntries)
+	}
+	var declared uint64
+	for _, f := range r.File {
+		if !safeZipPath(f.Name) {
+			return fmt.Errorf("%w: %q", ErrZipUnsafePath, f.Name)
+		}
+		declared += f.UncompressedSize64
+		if declared > uint64(limits.MaxDecompressedSize) {
+			return fmt.Errorf("%w: more than %d bytes decompressed", ErrZipLimitExceeded, limits.MaxDecompressedSize)
+		}
+	}
+	return nil
+}
+
+// copy decompresses an entry into w, the declared sizes can't be trusted so the actual bytes are counted too
+func (limits ZipLimits) copy(w io.Writer, entry io.Reader) error {
+	written, err := io.Copy(w, io.LimitReader(entry, limits.MaxDecompressedSize+1))
+	if err != nil {
+		return err
+	}
+	if written > limits.MaxDecompressedSize {
+		return fmt.Errorf("%w: more than %d bytes decompressed", ErrZipLimitExceeded, limits.MaxDecompressedSize)
+	}
+	return nil
+}
+
+func safeZipPath(name string) bool {
+	if name == "" || strings.Contains(name, "\\") || strings.HasPrefix(name, "/") || strings.Contains(name, ":") {
+		return false
+	}
+	cleaned := path.Clean(name)
+	return cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
@@ -0,0 +1,125 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSafeZipPath(t *testing.T) {
+	tests := []struct {
+		name string
+		safe bool
+	}{
+		{"Data/Extracts/sales.hyper", true},
+		{"sales.tds", true},
+		{"a/../b.tds", true},
+		{"./sales.tds", true},
+		{"..safe.tds", true},
+		{"", false},
+		{"..", false},
+		{"../sales.tds", false},
+		{"a/../../sales.tds", false},
+		{"/etc/passwd", false},
+		{"..\\sales.tds", false},
+		{"Data\\sales.hyper", false},
+		{"C:/Windows/win.ini", false},
+		{"c:sales.tds", false},
+	}
+	for _, test := range tests {
+		if safe := safeZipPath(test.name); safe != test.safe {
+			t.Errorf("safeZipPath(%q) = %v, want %v", test.name, safe, test.safe)
+		}
+	}
+}
+
+// builds an archive with an entry per name holding size bytes
+func zipReader(t *testing.T, size int, names ...string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range names {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = entry.Write(bytes.Repeat([]byte("x"), size)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader
+}
+
+func TestZipLimitsCheck(t *testing.T) {
+	limits := ZipLimits{MaxDecompressedSize: 100, MaxEntries: 2}
+	tests := []struct {
+		name    string
+		archive *zip.Reader
+		want    error
+	}{
+		{"within limits", zipReader(t, 50, "a.tds", "b.tds"), nil},
+		{"exactly at the size limit", zipReader(t, 50, "a.tds", "Data/b.hyper"), nil},
+		{"too many entries", zipReader(t, 1, "a.tds", "b.tds", "c.tds"), ErrZipLimitExceeded},
+		{"too large in total", zipReader(t, 51, "a.tds", "b.tds"), ErrZipLimitExceeded},
+		{"single entry too large", zipReader(t, 101, "a.tds"), ErrZipLimitExceeded},
+		{"path traversal", zipReader(t, 1, "../../a.tds"), ErrZipUnsafePath},
+		{"absolute path", zipReader(t, 1, "/tmp/a.tds"), ErrZipUnsafePath},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := limits.check(test.archive); !errors.Is(err, test.want) {
+				t.Fatalf("check returned %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestZipLimitsCopy(t *testing.T) {
+	limits := ZipLimits{MaxDecompressedSize: 10, MaxEntries: 1}
+	tests := []struct {
+		name string
+		size int
+		want error
+	}{
+		{"under the limit", 9, nil},
+		{"at the limit", 10, nil},
+		{"over the limit", 11, ErrZipLimitExceeded},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := limits.copy(ioutil.Discard, strings.NewReader(strings.Repeat("x", test.size)))
+			if !errors.Is(err, test.want) {
+				t.Fatalf("copy returned %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestZipLimitsDefaults(t *testing.T) {
+	api := &API{ZipLimits: ZipLimits{MaxEntries: 5}}
+	limits := api.zipLimits()
+	if limits.MaxEntries != 5 || limits.MaxDecompressedSize != DefaultMaxDecompressedSize {
+		t.Fatalf("zipLimits() = %+v, want 5 entries and the default size", limits)
+	}
+}